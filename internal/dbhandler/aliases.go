@@ -0,0 +1,148 @@
+package dbhandler
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/EZRA-DVLPR/GameList/internal/normalize"
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
+)
+
+// what to do instead of searching for a name that the sites never find
+type Alias struct {
+	// name as it comes from a store or the user. eg. "FINAL FANTASY VII REMAKE INTERGRADE"
+	Alias string
+
+	// name that is searched for instead. empty to search for the alias itself
+	SearchName string
+
+	// link to the page of the game on each source by source name
+	// a source with a link is fetched directly and not searched
+	URLs map[string]string
+}
+
+// name that is searched for on the sources
+func (alias Alias) searchName() string {
+	if alias.SearchName != "" {
+		return alias.SearchName
+	}
+	return alias.Alias
+}
+
+// adds the alias, or replaces the one with the same normalized name
+func (s *Store) SaveAlias(alias Alias) error {
+	alias.Alias = strings.TrimSpace(alias.Alias)
+	if alias.Alias == "" {
+		return fmt.Errorf("%w: alias has no name", ErrUnknownOption)
+	}
+
+	cols := []string{"aliasKey", "alias", "searchName"}
+	vals := []any{normalize.Key(alias.Alias), alias.Alias, strings.TrimSpace(alias.SearchName)}
+	for _, src := range scraper.Sources() {
+		cols = append(cols, urlColumn(src))
+		vals = append(vals, strings.TrimSpace(alias.URLs[src.Name()]))
+	}
+	_, err := s.db.Exec(
+		fmt.Sprintf(
+			"INSERT OR REPLACE INTO game_aliases (%s) VALUES (%s)",
+			join(cols, ", "),
+			join(placeholders(len(cols)), ","),
+		),
+		vals...,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving alias %s: %w", alias.Alias, err)
+	}
+
+	log.Println("Saved alias:", alias.Alias)
+	return nil
+}
+
+// removes the alias with the same normalized name
+func (s *Store) DeleteAlias(name string) error {
+	res, err := s.db.Exec("DELETE FROM game_aliases WHERE aliasKey = ?", normalize.Key(name))
+	if err != nil {
+		return fmt.Errorf("error deleting alias %s: %w", name, err)
+	}
+	if err := checkRowsAffected(res, name); err != nil {
+		return err
+	}
+
+	log.Println("Deleted alias:", name)
+	return nil
+}
+
+// returns every alias ordered by name
+func (s *Store) Aliases() ([]Alias, error) {
+	rows, err := s.db.Query(fmt.Sprintf("SELECT %s FROM game_aliases ORDER BY alias", aliasColumns()))
+	if err != nil {
+		return nil, fmt.Errorf("error obtaining aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var aliases []Alias
+	for rows.Next() {
+		alias, err := scanAlias(rows)
+		if err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+// returns the alias for the game name. names are compared once they are normalized
+func (s *Store) findAlias(gameName string) (alias Alias, found bool, err error) {
+	row := s.db.QueryRow(
+		fmt.Sprintf("SELECT %s FROM game_aliases WHERE aliasKey = ?", aliasColumns()),
+		normalize.Key(gameName),
+	)
+	alias, err = scanAlias(row)
+	if err == sql.ErrNoRows {
+		return Alias{Alias: gameName}, false, nil
+	} else if err != nil {
+		return Alias{Alias: gameName}, false, err
+	}
+
+	log.Println("Using alias for game:", gameName)
+	return alias, true, nil
+}
+
+// columns of game_aliases in the order scanAlias reads them
+func aliasColumns() string {
+	cols := []string{"alias", "searchName"}
+	for _, src := range scraper.Sources() {
+		cols = append(cols, urlColumn(src))
+	}
+	return join(cols, ", ")
+}
+
+// anything a single alias can be scanned from
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAlias(row rowScanner) (alias Alias, err error) {
+	sources := scraper.Sources()
+	urls := make([]string, len(sources))
+	dest := []any{&alias.Alias, &alias.SearchName}
+	for i := range urls {
+		dest = append(dest, &urls[i])
+	}
+	if err := row.Scan(dest...); err != nil {
+		if err == sql.ErrNoRows {
+			return alias, err
+		}
+		return alias, fmt.Errorf("error scanning alias: %w", err)
+	}
+
+	alias.URLs = map[string]string{}
+	for i, src := range sources {
+		if urls[i] != "" {
+			alias.URLs[src.Name()] = urls[i]
+		}
+	}
+	return alias, nil
+}
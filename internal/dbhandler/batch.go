@@ -0,0 +1,70 @@
+package dbhandler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/EZRA-DVLPR/GameList/model"
+)
+
+// searches and adds every game in gameNames to the DB, several at a time
+// a game that fails does not stop the others. all failures are returned together
+// cancelling ctx stops before the next game. games already added are kept
+func (s *Store) SearchAddAll(ctx context.Context, gameNames []string) error {
+	return runBatch(ctx, gameNames, s.SearchAddToDB)
+}
+
+// runs work for every game name with as many workers as the concurrency preference allows
+// each game is still one process for the progress bar, which work is expected to count
+func runBatch(ctx context.Context, gameNames []string, work func(context.Context, string) error) error {
+	workers := batchWorkers(len(gameNames))
+	log.Printf("Processing %d game(s) with %d worker(s)\n", len(gameNames), workers)
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	names := make(chan string)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for gameName := range names {
+				// a cancelled game is reported once for the whole batch
+				if err := work(ctx, gameName); err != nil && ctx.Err() == nil {
+					log.Println("Error processing game:", err)
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	// hand out the games until all of them are started or the batch is cancelled
+feed:
+	for _, gameName := range gameNames {
+		select {
+		case names <- gameName:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(names)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		log.Println("Batch cancelled")
+		errs = append(errs, ctx.Err())
+	}
+	return errors.Join(errs...)
+}
+
+// number of workers for a batch of n games. at least 1 and never more than there are games
+func batchWorkers(n int) int {
+	workers, _ := model.GetConcurrency()
+	return max(1, min(workers, n))
+}
@@ -0,0 +1,70 @@
+package dbhandler
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
+)
+
+// a column of the games table that can be shown in the table of the app
+type Column struct {
+	// name of the column in the games table. also used as the sort category
+	Name string
+	// shown on the header of the table
+	Header string
+	// holds a time in hours rather than text
+	Time bool
+}
+
+// every column that can be shown, in the order they are shown
+var Columns = []Column{
+	{Name: "name", Header: "Game Name"},
+	{Name: scraper.CategoryMain, Header: "Main Story", Time: true},
+	{Name: scraper.CategoryMainPlus, Header: "Main + Sides", Time: true},
+	{Name: scraper.CategoryComp, Header: "Completionist", Time: true},
+	{Name: scraper.CategoryCoop, Header: "Co-Op", Time: true},
+	{Name: scraper.CategoryVs, Header: "Vs.", Time: true},
+	{Name: scraper.CategorySpeedrun, Header: "Speedrun", Time: true},
+	{Name: scraper.FieldReleaseDate, Header: "Release Date"},
+	{Name: scraper.FieldPlatforms, Header: "Platforms"},
+	{Name: scraper.FieldGenres, Header: "Genres"},
+	{Name: scraper.FieldDeveloper, Header: "Developer"},
+	{Name: scraper.FieldPublisher, Header: "Publisher"},
+	{Name: "myPlatform", Header: "My Platform"},
+	{Name: "coverURL", Header: "Cover"},
+}
+
+// columns shown until others are chosen
+var DefaultColumns = []string{"name", scraper.CategoryMain, scraper.CategoryMainPlus, scraper.CategoryComp}
+
+// finds the column with the given name
+func GetColumn(name string) (Column, bool) {
+	for _, col := range Columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return Column{}, false
+}
+
+// the columns named in the comma separated list, in the order of Columns
+// unknown names are skipped. the game name is always the first column
+func ParseColumns(list string) (cols []Column) {
+	names := strings.Split(list, ",")
+	for _, col := range Columns {
+		if col.Name == "name" || slices.Contains(names, col.Name) {
+			cols = append(cols, col)
+		}
+	}
+	return
+}
+
+// the names of the columns as a comma separated list, as saved in the preferences
+func JoinColumns(cols []Column) string {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = col.Name
+	}
+	return strings.Join(names, ",")
+}
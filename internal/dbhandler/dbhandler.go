@@ -1,241 +1,503 @@
 package dbhandler
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/EZRA-DVLPR/GameList/internal/normalize"
 	"github.com/EZRA-DVLPR/GameList/internal/scraper"
 	"github.com/EZRA-DVLPR/GameList/model"
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// creates the DB with table
-func CreateDB() {
-	log.Println("Creating the DB")
+// Store owns a single connection pool to one games database
+// all operations on the games table are methods on it
+type Store struct {
+	db *sql.DB
+}
 
-	db, err := sql.Open("sqlite3", "games.db")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	_, err = db.Exec(`
-	CREATE TABLE IF NOT EXISTS games (
-		name TEXT PRIMARY KEY,
-		hltburl TEXT,
-		completionatorurl TEXT,
-		favorite INTEGER,
-		main REAL,
-		mainPlus REAL,
-		comp REAL
-	);
-	`)
+// opens (and creates if needed) the sqlite database located at path
+// the schema is migrated to the newest version before it is returned
+func Open(path string) (*Store, error) {
+	log.Println("Opening DB at:", path)
+
+	// games in a batch are saved from several goroutines, so writers wait for the lock
+	// instead of failing, and transactions take the write lock when they begin
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_txlock=immediate")
 	if err != nil {
-		log.Fatal("Error creating games table:", err)
+		return nil, err
 	}
 
-	log.Println("Created the local DB successfully")
-}
+	// sql.Open is lazy, so make sure the file can actually be used
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
 
-func CheckDBExists() bool {
-	db, err := sql.Open("sqlite3", "games.db")
+	s, err := NewStore(db)
 	if err != nil {
-		log.Fatal("Error opening db:", err)
+		db.Close()
+		return nil, err
 	}
-	defer db.Close()
+	return s, nil
+}
 
-	var name string
-	err = db.QueryRow("SELECT name FROM sqlite_master WHERE type='table' AND name = ?", "games").Scan(&name)
-	log.Println("Checking if table exists")
-	if err == sql.ErrNoRows {
-		return false
-	} else if err != nil {
-		log.Fatal("Error checking table with no data:", err)
+// wraps an already opened database and migrates its schema. the caller keeps ownership of db
+func NewStore(db *sql.DB) (*Store, error) {
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		return nil, err
 	}
-	return true
+	return s, nil
+}
+
+// closes the underlying connection pool
+func (s *Store) Close() error {
+	return s.db.Close()
 }
 
-func DeleteAllDBData() {
-	db, err := sql.Open("sqlite3", "games.db")
+func (s *Store) DeleteAllDBData() error {
+	_, err := s.db.Exec("DELETE FROM games")
 	if err != nil {
-		log.Fatal("Error opening db:", err)
+		return fmt.Errorf("error deleting entire DB: %w", err)
 	}
-	defer db.Close()
-
-	_, err = db.Exec("DELETE FROM games")
+	_, err = s.db.Exec("DELETE FROM game_times")
+	if err != nil {
+		return fmt.Errorf("error deleting all source times: %w", err)
+	}
+	_, err = s.db.Exec("DELETE FROM game_platform_times")
 	if err != nil {
-		log.Fatal("Error deleting entire DB")
+		return fmt.Errorf("error deleting all platform times: %w", err)
 	}
 
 	log.Println("Deleted all data in DB")
+	return nil
 }
 
 // given a game struct, will search DB for the name of the game to delete it
-func DeleteFromDB(gameName string) {
-	db, err := sql.Open("sqlite3", "games.db")
+func (s *Store) DeleteFromDB(gameName string) error {
+	res, err := s.db.Exec("DELETE FROM games WHERE name = ?", gameName)
 	if err != nil {
-		log.Fatal("Failed to access db")
+		return fmt.Errorf("error deleting game from games table: %w", err)
 	}
 
-	res, err := db.Exec("DELETE FROM games WHERE name = ?", gameName)
-	if err != nil {
-		log.Fatal("Error deleting game from games table: ", err)
+	if err := checkRowsAffected(res, gameName); err != nil {
+		return err
 	}
-
-	if rowsAffected(res, gameName) {
-		log.Println("Game deleted: ", gameName)
+	if err := s.deleteSourceTimes(gameName); err != nil {
+		return err
 	}
+	log.Println("Game deleted: ", gameName)
+	return nil
 }
 
 // if the given game is not empty and not already existent in DB, then add to the DB
-func AddToDB(game scraper.Game) {
+func (s *Store) AddToDB(game scraper.Game) error {
 	// disregard games that have no time data
-	if (game.Main == -1) &&
-		(game.MainPlus == -1) &&
-		(game.Comp == -1) {
-		log.Println("No game data received for associate game.")
-		return
+	noTimes := true
+	for _, category := range scraper.Categories {
+		if game.Time(category) != -1 {
+			noTimes = false
+		}
 	}
-
-	db, err := sql.Open("sqlite3", "games.db")
-	if err != nil {
-		log.Fatal("Failed to access db")
+	if noTimes {
+		log.Println("No game data received for associate game.")
+		return fmt.Errorf("%w: %s", ErrNoTimeData, game.Name)
 	}
-	defer db.Close()
 
-	var exists bool
-	err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM games WHERE name = ?)", game.Name).Scan(&exists)
+	// the same game may be named differently by a store and a site. eg. "DOOM™ Eternal" and "Doom Eternal"
+	existing, exists, err := s.findSameGame(game.Name)
 	if err != nil {
-		log.Fatal("Error checking game existence", err)
+		return err
 	}
 	if exists {
-		log.Println("Game already exists in local DB! Skipping insertion")
-		return
+		log.Println("Game already exists in local DB as", existing, "! Skipping insertion")
+		return fmt.Errorf("%w: %s (saved as %s)", ErrDuplicateGame, game.Name, existing)
 	}
 
 	log.Println("Adding the game data to the local DB for game:", game.Name)
 
-	_, err = db.Exec(
-		"INSERT OR IGNORE INTO games (name, hltburl, completionatorurl, favorite, main, mainPlus, comp) VALUES (?,?,?,?,?,?,?)",
-		game.Name,
-		game.HLTBUrl,
-		game.CompletionatorUrl,
-		game.Favorite,
-		game.Main,
-		game.MainPlus,
-		game.Comp,
+	// every source has its own column for the link to the game's page
+	cols := []string{"name", "nameKey", "favorite"}
+	vals := []any{game.Name, normalize.Key(game.Name), game.Favorite}
+	for _, category := range scraper.Categories {
+		cols = append(cols, category)
+		vals = append(vals, game.Time(category))
+	}
+	for _, src := range scraper.Sources() {
+		cols = append(cols, urlColumn(src))
+		vals = append(vals, src.URL(game))
+	}
+	for _, field := range scraper.Fields {
+		cols = append(cols, field)
+		vals = append(vals, game.Field(field))
+	}
+	cols = append(cols, "coverURL")
+	vals = append(vals, game.CoverURL)
+	_, err = s.db.Exec(
+		fmt.Sprintf(
+			"INSERT OR IGNORE INTO games (%s) VALUES (%s)",
+			join(cols, ", "),
+			join(placeholders(len(cols)), ","),
+		),
+		vals...,
 	)
 	if err != nil {
-		log.Fatal("Error inserting game: ", err)
+		return fmt.Errorf("error inserting game %s: %w", game.Name, err)
 	}
 
 	log.Println("Finished adding the game data to the local DB for game:", game.Name)
-	model.IncrementProgress()
+	return nil
 }
 
-// given the name of a game & search source(s), add struct to DB
-func SearchAddToDB(gameName string) {
-	// get the data from scraper using sources
-	var newgame scraper.Game
+// returns the name of the saved game that is the same game as gameName once both are normalized
+// the name itself is preferred over another game with the same key
+func (s *Store) findSameGame(gameName string) (existing string, found bool, err error) {
+	err = s.db.QueryRow(
+		"SELECT name FROM games WHERE name = ? OR nameKey = ? ORDER BY name = ? DESC LIMIT 1",
+		gameName,
+		normalize.Key(gameName),
+		gameName,
+	).Scan(&existing)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("error checking game existence: %w", err)
+	}
+	return existing, true, nil
+}
 
-	searchSource, _ := model.GetSearchSource()
-	switch searchSource {
-	case "All":
-		log.Println("Searching from all sources for game data for game:", gameName)
-
-		// search both and obtain game structs from each source
-		hltbSearch := scraper.SearchGameHLTB(gameName)
-		completionatorSearch := scraper.SearchGameCompletionator(gameName)
-		newgame = compareGetGameData(hltbSearch, completionatorSearch)
-		newgame.Name = gameName
+// anything statements can be run on. either the DB or a transaction
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// sets the name key of every game from its name
+// needed after games are written without one. eg. imported from a file made before the key existed
+func updateNameKeys(db execer) error {
+	rows, err := db.Query("SELECT name FROM games")
+	if err != nil {
+		return fmt.Errorf("error reading game names: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("error reading game names: %w", err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading game names: %w", err)
+	}
+
+	for _, name := range names {
+		if _, err := db.Exec("UPDATE games SET nameKey = ? WHERE name = ?", normalize.Key(name), name); err != nil {
+			return fmt.Errorf("error saving name key of game %s: %w", name, err)
+		}
+	}
+	return nil
+}
 
-	case "HLTB":
-		log.Println("Searching HLTB for game data for game:", gameName)
-		newgame = scraper.SearchGameHLTB(gameName)
+// given the name of a game & search source(s), add struct to DB
+// counts as one process for the progress bar whether or not it succeeds, unless it was cancelled
+func (s *Store) SearchAddToDB(ctx context.Context, gameName string) error {
+	_, err := s.searchAdd(ctx, gameName)
+	return err
+}
 
-	case "Completionator":
-		log.Println("Searching Completionator for game data for game:", gameName)
-		newgame = scraper.SearchGameCompletionator(gameName)
+// searches for the game and adds it to the DB like SearchAddToDB
+// returns the name the game was added as, or already saved under, once it was found. o/w empty
+func (s *Store) searchAdd(ctx context.Context, gameName string) (savedName string, err error) {
+	defer countProcess(ctx)
 
-	default:
+	sources, err := selectedSources()
+	if err != nil {
 		log.Println("No such search style. Aborting process")
-		return
+		return "", err
 	}
 
-	// with the data retrieved, add it to DB
-	AddToDB(newgame)
+	// a name the sites never find may have an alias saved by the user
+	alias, _, err := s.findAlias(gameName)
+	if err != nil {
+		return "", err
+	}
+
+	// get the data from scraper using sources
+	results, err := searchSources(ctx, sources, alias)
+	if err != nil {
+		return "", err
+	}
+	return s.addResults(gameName, sources, results)
 }
 
-// given a game name, will update its contents with newer information
-func UpdateGame(gameName string) {
-	db, err := sql.Open("sqlite3", "games.db")
+// returns the games found on each selected source for the name, best match first, by source name
+// a source that fails is left out. an error is only returned if every source failed
+func (s *Store) SearchCandidates(ctx context.Context, gameName string) (map[string][]scraper.Candidate, error) {
+	sources, err := selectedSources()
 	if err != nil {
-		log.Fatal("Failed to access db")
+		return nil, err
+	}
+	alias, _, err := s.findAlias(gameName)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := map[string][]scraper.Candidate{}
+	var errs []error
+	for _, src := range sources {
+		// the link saved in the alias is the game the user wants, so there is nothing to choose
+		if url := alias.URLs[src.Name()]; url != "" {
+			candidates[src.Name()] = []scraper.Candidate{{Title: alias.searchName(), URL: url, Score: 1}}
+			continue
+		}
+
+		log.Printf("Searching %s for games named: %s\n", src.Name(), alias.searchName())
+		found, err := scraper.SearchCandidates(ctx, src, alias.searchName())
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			log.Println("Error searching", src.Name(), err)
+			errs = append(errs, err)
+			continue
+		}
+		candidates[src.Name()] = found
+	}
+
+	if len(candidates) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return candidates, nil
+}
+
+// returns the best match of each source, and whether all of them are good enough to use without asking
+func BestCandidates(candidates map[string][]scraper.Candidate) (best map[string]scraper.Candidate, confident bool) {
+	best = map[string]scraper.Candidate{}
+	confident = true
+	for name, found := range candidates {
+		if len(found) == 0 {
+			continue
+		}
+		best[name] = found[0]
+		if found[0].Score < scraper.ConfidentScore {
+			confident = false
+		}
 	}
-	defer db.Close()
+	return best, confident
+}
+
+// fetches the chosen game from each source and adds the data to the DB
+// counts as one process for the progress bar whether or not it succeeds, unless it was cancelled
+func (s *Store) AddChosen(ctx context.Context, gameName string, chosen map[string]scraper.Candidate) error {
+	defer countProcess(ctx)
+
+	var sources []scraper.Source
+	var results []sourceResult
+	var errs []error
+	for _, src := range scraper.Sources() {
+		candidate, ok := chosen[src.Name()]
+		if !ok {
+			continue
+		}
+		sources = append(sources, src)
+
+		log.Printf("Obtaining data from %s for chosen game: %s\n", src.Name(), candidate.Title)
+		game, err := src.Fetch(ctx, candidate.URL)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			log.Println("Error obtaining data from", src.Name(), err)
+			errs = append(errs, err)
+			continue
+		}
+		results = append(results, sourceResult{source: src, game: game})
+	}
+
+	if len(results) == 0 {
+		return errors.Join(errs...)
+	}
+	_, err := s.addResults(gameName, sources, results)
+	return err
+}
 
+// merges what the sources found and adds it to the DB along with what each source reported
+// returns the name the game was added as. a game that is already saved gives ErrDuplicateGame along with it
+func (s *Store) addResults(gameName string, sources []scraper.Source, results []sourceResult) (string, error) {
+	// a single source keeps the name of the game as it is on the site
+	// o/w the sites may disagree, so use the name that was searched
+	var newgame scraper.Game
+	if len(results) == 1 && len(sources) == 1 {
+		newgame = results[0].game
+	} else {
+		newgame = compareGetGameData(results)
+		newgame.Name = gameName
+	}
+
+	// with the data retrieved, add it to DB along with what each source reported
+	if err := s.AddToDB(newgame); err != nil {
+		return newgame.Name, err
+	}
+	return newgame.Name, s.saveSourceTimes(newgame.Name, results)
+}
+
+// given a game name, will update its contents with newer information from every source
+// counts as one process for the progress bar whether or not it succeeds, unless it was cancelled
+func (s *Store) UpdateGame(ctx context.Context, gameName string) error {
+	defer countProcess(ctx)
+	return s.updateGame(ctx, gameName, nil)
+}
+
+// updates the game like UpdateGame, but uses the data in fetched for the sources it has, by source name
+// instead of getting it from the site again
+func (s *Store) updateGame(ctx context.Context, gameName string, fetched map[string]scraper.Game) error {
 	// get urls for given game
-	var hltbURL, completionatorURL string
-	err = db.QueryRow("SELECT hltburl, completionatorurl FROM games WHERE name = ?", gameName).Scan(&hltbURL, &completionatorURL)
+	sources := scraper.Sources()
+	urls, err := s.savedURLs(gameName, sources)
+	if err != nil {
+		return err
+	}
+
+	// a game with no link for a source is searched for using its alias
+	alias, _, err := s.findAlias(gameName)
 	if err != nil {
-		log.Fatal("Error obtaining URLs for given game")
+		return err
 	}
 
-	// if no URL from source(s), then perform searches for game page link
+	// if no URL from source, then perform search for game page link
 	// o/w directly scrape from the saved page
-	// WARN: if there is another search source, consider making a single array of search sources
-	// and do: if for each search source url = "", searchgamesearchsource, else fetchsearchsource
-	var hltbSearch, completionatorSearch scraper.Game
-	if hltbURL == "" {
-		log.Println("No URL found to obtain information from HLTB. Attempting to get link")
-		hltbSearch = scraper.SearchGameHLTB(gameName)
-	} else {
-		log.Println("Directly obtaining data from HLTB with saved link")
-		hltbSearch = scraper.FetchHLTB(hltbURL)
+	var results []sourceResult
+	var errs []error
+	for i, src := range sources {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var game scraper.Game
+		if prefetched, ok := fetched[src.Name()]; ok {
+			log.Printf("Using data from %s that was just obtained\n", src.Name())
+			game, err = prefetched, nil
+		} else if urls[i] == "" && alias.URLs[src.Name()] != "" {
+			log.Printf("Obtaining data from %s with the link saved in the alias\n", src.Name())
+			game, err = src.Fetch(ctx, alias.URLs[src.Name()])
+		} else if urls[i] == "" {
+			log.Printf("No URL found to obtain information from %s. Attempting to get link\n", src.Name())
+			game, err = scraper.SearchGame(ctx, src, alias.searchName())
+		} else {
+			log.Printf("Directly obtaining data from %s with saved link\n", src.Name())
+			game, err = src.Fetch(ctx, urls[i])
+		}
+		if err != nil {
+			log.Println("Error obtaining data from", src.Name(), err)
+			errs = append(errs, err)
+			continue
+		}
+		results = append(results, sourceResult{source: src, game: game})
 	}
-	if completionatorURL == "" {
-		log.Println("No URL found to obtain information from Completionator. Attempting to get link")
-		completionatorSearch = scraper.SearchGameCompletionator(gameName)
-	} else {
-		log.Println("Directly obtaining data from Completionator with saved link")
-		completionatorSearch = scraper.FetchCompletionator(completionatorURL)
+
+	// a cancelled update leaves the saved data as it was
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
 
-	newgamedata := compareGetGameData(hltbSearch, completionatorSearch)
+	// keep the saved data if no source gave anything new
+	if len(results) == 0 {
+		return errors.Join(errs...)
+	}
+
+	newgamedata := compareGetGameData(results)
+
+	// a source that failed keeps the link that was saved for it
+	for i, src := range sources {
+		if src.URL(newgamedata) == "" {
+			src.SetURL(&newgamedata, urls[i])
+		}
+	}
 
 	// overwrite the old data with the new Data
 	log.Println("Overwriting saved data for game:", gameName)
-	rows, err := db.Exec(
-		"UPDATE games SET hltburl = ?, completionatorurl = ?, main = ?, mainPlus = ?, comp = ? WHERE name = ?",
-		newgamedata.HLTBUrl,
-		newgamedata.CompletionatorUrl,
-		newgamedata.Main,
-		newgamedata.MainPlus,
-		newgamedata.Comp,
-		gameName,
+	var sets []string
+	var vals []any
+	for _, category := range scraper.Categories {
+		sets = append(sets, category+" = ?")
+		vals = append(vals, newgamedata.Time(category))
+	}
+	for _, src := range sources {
+		sets = append(sets, urlColumn(src)+" = ?")
+		vals = append(vals, src.URL(newgamedata))
+	}
+	// details no source gave this time keep what was saved for them
+	for _, field := range scraper.Fields {
+		sets = append(sets, fmt.Sprintf("%[1]s = COALESCE(NULLIF(?, ''), %[1]s)", field))
+		vals = append(vals, newgamedata.Field(field))
+	}
+	sets = append(sets, "coverURL = COALESCE(NULLIF(?, ''), coverURL)")
+	vals = append(vals, newgamedata.CoverURL)
+	rows, err := s.db.Exec(
+		fmt.Sprintf("UPDATE games SET %s WHERE name = ?", join(sets, ", ")),
+		append(vals, gameName)...,
 	)
 	if err != nil {
-		log.Println("Error updating value in table for game:", gameName)
-		log.Println(err)
-		return
+		return fmt.Errorf("error updating value in table for game %s: %w", gameName, err)
 	}
-	if rowsAffected(rows, gameName) {
-		log.Println("Successfully updated values for game:", gameName)
+	if err := checkRowsAffected(rows, gameName); err != nil {
+		return err
 	}
-	model.IncrementProgress()
+	if err := s.saveSourceTimes(gameName, results); err != nil {
+		return err
+	}
+	log.Println("Successfully updated values for game:", gameName)
+	return nil
 }
 
-func UpdateEntireDB() {
-	db, err := sql.Open("sqlite3", "games.db")
-	if err != nil {
-		log.Fatal("Failed to access db")
+// returns the saved link for each of the given sources, in the same order
+func (s *Store) savedURLs(gameName string, sources []scraper.Source) ([]string, error) {
+	cols := make([]string, len(sources))
+	for i, src := range sources {
+		cols[i] = urlColumn(src)
 	}
-	defer db.Close()
 
-	rows, err := db.Query("SELECT name FROM games")
+	// links may be NULL for games imported from CSV
+	nullURLs := make([]sql.NullString, len(sources))
+	ptrs := make([]any, len(sources))
+	for i := range nullURLs {
+		ptrs[i] = &nullURLs[i]
+	}
+
+	err := s.db.QueryRow(
+		fmt.Sprintf("SELECT %s FROM games WHERE name = ?", join(cols, ", ")),
+		gameName,
+	).Scan(ptrs...)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrGameNotFound, gameName)
+	} else if err != nil {
+		return nil, fmt.Errorf("error obtaining URLs for game %s: %w", gameName, err)
+	}
+
+	urls := make([]string, len(sources))
+	for i, url := range nullURLs {
+		urls[i] = url.String
+	}
+	return urls, nil
+}
+
+// updates every game in the DB, several at a time. a game that fails to update does not stop the others
+// all failures are returned together. cancelling ctx stops before the next game
+func (s *Store) UpdateEntireDB(ctx context.Context) error {
+	rows, err := s.db.Query("SELECT name FROM games")
+	if err != nil {
+		return fmt.Errorf("error obtaining game names from games table: %w", err)
+	}
 	defer rows.Close()
 
 	// for each row, get game name and append to list of game names
@@ -252,53 +514,49 @@ func UpdateEntireDB() {
 	}
 
 	log.Println("List of game names obtained. Now updating each game found")
-	for _, gameName := range gameNames {
-		log.Println("Updating game:", gameName)
-		UpdateGame(gameName)
-	}
-
-	log.Println("All games updated")
+	err = runBatch(ctx, gameNames, s.UpdateGame)
+	log.Println("Finished updating games")
+	return err
 }
 
 // if the given game is not empty, then toggle favorite
-func ToggleFavorite(gameName string) {
-	db, err := sql.Open("sqlite3", "games.db")
-	if err != nil {
-		log.Fatal("Failed to access db")
-	}
-	defer db.Close()
-
+func (s *Store) ToggleFavorite(gameName string) error {
 	// get value of favorite for given game
 	var favorite bool
-	err = db.QueryRow("SELECT favorite FROM games WHERE name = ?", gameName).Scan(&favorite)
-	if err != nil {
-		log.Fatal("Error obtaining favorite value from game", err)
+	err := s.db.QueryRow("SELECT favorite FROM games WHERE name = ?", gameName).Scan(&favorite)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s", ErrGameNotFound, gameName)
+	} else if err != nil {
+		return fmt.Errorf("error obtaining favorite value from game %s: %w", gameName, err)
 	}
 
 	// update game favorite value to the opposite value
-	res, err := db.Exec("UPDATE games SET favorite = ? WHERE name = ?", !favorite, gameName)
+	res, err := s.db.Exec("UPDATE games SET favorite = ? WHERE name = ?", !favorite, gameName)
 	if err != nil {
-		log.Fatal("Error updating game to be favorite", err)
+		return fmt.Errorf("error updating game %s to be favorite: %w", gameName, err)
 	}
 
-	if rowsAffected(res, gameName) {
-		log.Println("Toggled Favorite for given game:", gameName)
+	if err := checkRowsAffected(res, gameName); err != nil {
+		return err
 	}
+	log.Println("Toggled Favorite for given game:", gameName)
+	return nil
 }
 
 // returns query from db as [][]string given cat, ord, and query
-func SortDB() (dbOutput [][]string) {
-	db, err := sql.Open("sqlite3", "games.db")
-	if err != nil {
-		log.Fatal("Error accessing local dB: ", err)
-	}
-	defer db.Close()
-
+// each row holds the shown columns in the order of ShownColumns
+func (s *Store) SortDB() (dbOutput [][]string, err error) {
 	// get values for processing
 	sortOrder, _ := model.GetSortOrder()
 	sortCategory, _ := model.GetSortCategory()
 	st, _ := model.GetSearchText()
 	queryName := strings.TrimSpace(st)
+	cols := ShownColumns()
+
+	// the sort category is put in the query, so only a known column is used
+	if _, ok := GetColumn(sortCategory); !ok {
+		sortCategory = "name"
+	}
 
 	// if sortOrder is true => ASC. false => DESC
 	so := ""
@@ -308,60 +566,102 @@ func SortDB() (dbOutput [][]string) {
 		so = "DESC"
 	}
 
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = col.Name
+	}
+
+	// every column can be sorted by, shown or not. times are those on the platform the game is owned on
+	inner := []string{"name", "favorite"}
+	for _, col := range Columns[1:] {
+		if slices.Contains(scraper.PlatformCategories, col.Name) {
+			inner = append(inner, platformTimeColumn(col.Name))
+		} else {
+			inner = append(inner, col.Name)
+		}
+	}
+
 	// if queryName is empty, sort DB without searching for similar game names
-	var rows *sql.Rows
+	where := ""
+	var args []any
+	if queryName != "" {
+		where = "WHERE name LIKE ?"
+		args = append(args, "%"+queryName+"%")
+	}
+
+	// times sort by their value and the details as text, which keeps ISO release dates in order
+	// names that start with a number sort by it. eg. 1234 < 12345, abcd < abcde, etc.
+	order := sortCategory
+	if sortCategory == "name" {
+		order = "CASE WHEN name GLOB '[0-9]*' THEN CAST(name AS INTEGER) ELSE name END"
+	}
+
 	log.Println("Sorting DB with given inputs:", sortCategory, sortOrder, queryName)
-	if queryName == "" {
-		rows, err = db.Query(
-			// order values based on their value comparison
-			// eg. 1234 < 12345, abcd < abcde, etc.
-			fmt.Sprintf(`
-				SELECT name, main, mainPlus, comp 
-				FROM games 
-				ORDER BY favorite DESC, 
-				CASE 
-					WHEN typeof(%[1]s) = 'integer' OR %[1]s GLOB '[0-9]*' THEN CAST(%[1]s AS INTEGER) 
-					ELSE %[1]s 
-				END %[2]s;`,
-				sortCategory,
-				so,
-			),
-		)
-	} else {
-		rows, err = db.Query(
-			fmt.Sprintf(`
-				SELECT name, main, mainPlus, comp 
-				FROM games 
-				WHERE name LIKE ?
-				ORDER BY favorite DESC, 
-				CASE 
-					WHEN typeof(%[1]s) = 'integer' OR %[1]s GLOB '[0-9]*' THEN CAST(%[1]s AS INTEGER) 
-					ELSE %[1]s 
-				END %[2]s;`,
-				sortCategory,
-				so,
-			), "%"+queryName+"%")
+	rows, err := s.db.Query(
+		fmt.Sprintf(`
+			SELECT %[1]s 
+			FROM (SELECT %[5]s FROM games %[2]s) 
+			ORDER BY favorite DESC, %[3]s %[4]s;`,
+			join(names, ", "),
+			where,
+			order,
+			so,
+			join(inner, ", "),
+		),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error sorting games from games table: %w", err)
 	}
+	defer rows.Close()
+
+	// times from only a few players are marked so they are not trusted as much
+	lowConfidence, err := s.lowConfidenceTimes()
 	if err != nil {
-		log.Fatal("Error sorting games from games table: ", err)
+		return nil, err
 	}
 
 	// format data for return
 	for rows.Next() {
-		var name string
-		var main, mainPlus, comp float64
-		if err := rows.Scan(&name, &main, &mainPlus, &comp); err != nil {
-			log.Fatal("Error scanning row: ", err)
+		values := make([]sql.NullString, len(cols))
+		times := make([]sql.NullFloat64, len(cols))
+		ptrs := make([]any, len(cols))
+		for i, col := range cols {
+			if col.Time {
+				ptrs[i] = &times[i]
+			} else {
+				ptrs[i] = &values[i]
+			}
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+
+		row := make([]string, len(cols))
+		for i, col := range cols {
+			if col.Time {
+				// times are float32 in the games, so more digits are only the rounding of float32
+				row[i] = strconv.FormatFloat(times[i].Float64, 'f', -1, 32)
+				if lowConfidence[values[0].String][col.Name] && times[i].Float64 > 0 {
+					row[i] += LowConfidenceMark
+				}
+			} else {
+				row[i] = values[i].String
+			}
 		}
-		dbOutput = append(dbOutput, []string{
-			name,
-			strconv.FormatFloat(main, 'f', -1, 64),
-			strconv.FormatFloat(mainPlus, 'f', -1, 64),
-			strconv.FormatFloat(comp, 'f', -1, 64),
-		})
+		dbOutput = append(dbOutput, row)
 	}
 	log.Println("DB has been sorted with given options:", sortCategory, sortOrder, queryName)
-	return dbOutput
+	return dbOutput, rows.Err()
+}
+
+// the columns chosen to be shown in the table. the defaults if none have been chosen
+func ShownColumns() []Column {
+	list, _ := model.GetColumns()
+	if list == "" {
+		list = strings.Join(DefaultColumns, ",")
+	}
+	return ParseColumns(list)
 }
 
 func convertRowToInterface(row []string) []any {
@@ -372,6 +672,15 @@ func convertRowToInterface(row []string) []any {
 	return result
 }
 
+// returns n "?" to be used as the values of a query
+func placeholders(n int) []string {
+	result := make([]string, n)
+	for i := range result {
+		result[i] = "?"
+	}
+	return result
+}
+
 func join(elements []string, sep string) string {
 	if len(elements) == 0 {
 		return ""
@@ -383,44 +692,115 @@ func join(elements []string, sep string) string {
 	return result
 }
 
-// if given rows were affected then returns true. o/w false
-func rowsAffected(res sql.Result, gameName string) (wereAffected bool) {
+// if no rows were affected then the game was not in the DB and returns ErrGameNotFound
+func checkRowsAffected(res sql.Result, gameName string) error {
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		log.Fatal("Error checking affected rows: ", err)
+		return fmt.Errorf("error checking affected rows: %w", err)
 	}
 	if rowsAffected == 0 {
 		log.Printf("Game `%s` not found in local database\n", gameName)
-		return false
+		return fmt.Errorf("%w: %s", ErrGameNotFound, gameName)
+	}
+	return nil
+}
+
+// a game that was obtained from a source
+type sourceResult struct {
+	source scraper.Source
+	game   scraper.Game
+}
+
+// returns the registered sources chosen by the search source preference
+func selectedSources() ([]scraper.Source, error) {
+	searchSource, _ := model.GetSearchSource()
+	if searchSource == scraper.AllSources {
+		return scraper.Sources(), nil
+	}
+
+	src, ok := scraper.GetSource(searchSource)
+	if !ok {
+		return nil, fmt.Errorf("%w: search source %q", ErrUnknownOption, searchSource)
+	}
+	return []scraper.Source{src}, nil
+}
+
+// searches each of the sources for the game, or fetches it from the link in its alias
+// a failure from some of the sources still leaves usable data from the others
+func searchSources(ctx context.Context, sources []scraper.Source, alias Alias) (results []sourceResult, err error) {
+	var errs []error
+	for _, src := range sources {
+		var game scraper.Game
+		if url := alias.URLs[src.Name()]; url != "" {
+			log.Printf("Obtaining data from %s with the link saved in the alias for: %s\n", src.Name(), alias.Alias)
+			game, err = src.Fetch(ctx, url)
+		} else {
+			log.Printf("Searching %s for game data for game: %s\n", src.Name(), alias.searchName())
+			game, err = scraper.SearchGame(ctx, src, alias.searchName())
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			log.Println("Error searching", src.Name(), err)
+			errs = append(errs, err)
+			continue
+		}
+		results = append(results, sourceResult{source: src, game: game})
+	}
+
+	if len(results) == 0 {
+		return nil, errors.Join(errs...)
 	}
-	return true
+	return results, nil
 }
 
-// WARN: if there is another search source, consider making a single parameter of
-// []scraper.Game and go by ref to do comparisons more effectively
-func compareGetGameData(
-	firstGame scraper.Game,
-	secondGame scraper.Game,
-) (resultGame scraper.Game) {
-	// if both are empty, then dont update anything (as no new data was found)
-	if firstGame.Main == 0 &&
-		firstGame.MainPlus == 0 &&
-		firstGame.Comp == 0 &&
-		secondGame.Main == 0 &&
-		secondGame.MainPlus == 0 &&
-		secondGame.Comp == 0 {
-		log.Println("No Game Data for games Found!")
-		return
+// counts one process for the progress bar. a process cut short by cancelling is not counted
+func countProcess(ctx context.Context) {
+	if ctx.Err() == nil {
+		model.IncrementProgress()
 	}
+}
+
+// name of the column in the games table that holds the link to the game's page on the source
+// eg. "HLTB" -> "hltburl"
+func urlColumn(src scraper.Source) string {
+	return strings.ToLower(src.Name()) + "url"
+}
+
+// merges the data from each source into a single game using the merge strategy preference
+func compareGetGameData(results []sourceResult) (resultGame scraper.Game) {
+	strategy := currentMergeStrategy()
 
 	// save each url
-	resultGame.HLTBUrl = firstGame.HLTBUrl
-	resultGame.CompletionatorUrl = secondGame.CompletionatorUrl
+	for _, res := range results {
+		res.source.SetURL(&resultGame, res.source.URL(res.game))
+	}
 
-	// compare the values of each game and take the higher
-	resultGame.Main = max(firstGame.Main, secondGame.Main)
-	resultGame.MainPlus = max(firstGame.MainPlus, secondGame.MainPlus)
-	resultGame.Comp = max(firstGame.Comp, secondGame.Comp)
+	// details are taken from the first source that has them
+	for _, res := range results {
+		for _, field := range scraper.Fields {
+			resultGame.SetField(field, res.game.Field(field))
+		}
+		if resultGame.CoverURL == "" {
+			resultGame.CoverURL = res.game.CoverURL
+		}
+	}
+
+	// merge only the values that each source has for every category
+	for _, category := range scraper.Categories {
+		var values []sourceValue
+		for _, res := range results {
+			if value := res.game.Time(category); value > 0 {
+				values = append(values, sourceValue{
+					source: res.source.Name(),
+					value:  value,
+					polled: res.game.Polled[category],
+				})
+			}
+		}
+		resultGame.SetTime(category, mergeValues(strategy, values))
+	}
 
 	// NOTE: the game that is returned has no name
 	return
@@ -0,0 +1,31 @@
+package dbhandler
+
+import (
+	"errors"
+
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
+)
+
+// errors returned by the Store. compare with errors.Is since they are wrapped with the game name
+var (
+	// no row in the games table has the given name
+	ErrGameNotFound = errors.New("game not found in local database")
+
+	// a row with the same name already exists in the games table
+	ErrDuplicateGame = errors.New("game already exists in local database")
+
+	// the game has no Main, Main + Sides, Completionist, Co-Op, Vs., nor Speedrun time to save
+	ErrNoTimeData = scraper.ErrNoTimeData
+
+	// a source loaded the page of the game, but its layout changed so nothing could be read from it
+	ErrLayoutChanged = scraper.ErrLayoutChanged
+
+	// a link given for a source is not the page of a game on that source
+	ErrNotGamePage = errors.New("link is not the page of a game")
+
+	// the chosen search source, import or export option is unknown
+	ErrUnknownOption = errors.New("no such option exists")
+
+	// the file given for importing has nothing usable in it
+	ErrEmptyImport = errors.New("file to import is empty or improperly formatted")
+)
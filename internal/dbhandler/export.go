@@ -1,7 +1,6 @@
 package dbhandler
 
 import (
-	"database/sql"
 	"encoding/csv"
 	"fmt"
 	"log"
@@ -12,7 +11,7 @@ import (
 )
 
 // selector for exporting
-func Export(choice int, filename string) {
+func (s *Store) Export(choice int, filename string) error {
 	// check filename for extension and remove it
 	hasExt := strings.Index(filename, ".")
 	if hasExt != -1 {
@@ -21,30 +20,24 @@ func Export(choice int, filename string) {
 
 	switch choice {
 	case 1:
-		exportCSV(filename)
+		return s.exportCSV(filename)
 	case 2:
-		exportSQL(filename)
+		return s.exportSQL(filename)
 	case 3:
-		exportMarkdown(filename)
+		return s.exportMarkdown(filename)
 	default:
-		log.Fatal("No such export exists!")
+		return fmt.Errorf("%w: export %d", ErrUnknownOption, choice)
 	}
 }
 
-func exportCSV(filename string) {
+func (s *Store) exportCSV(filename string) error {
 	log.Println("Exporting to CSV")
 
-	db, err := sql.Open("sqlite3", "games.db")
-	if err != nil {
-		log.Fatal("Error opening database for export", err)
-	}
-	defer db.Close()
-
 	// get all data from table
 	log.Println("Getting all game data")
-	rows, err := db.Query("SELECT * FROM games")
+	rows, err := s.db.Query("SELECT * FROM games")
 	if err != nil {
-		log.Fatal("Error retrieving data:", err)
+		return fmt.Errorf("error retrieving data: %w", err)
 	}
 	defer rows.Close()
 
@@ -52,13 +45,13 @@ func exportCSV(filename string) {
 	log.Println("Getting column names")
 	cols, err := rows.Columns()
 	if err != nil {
-		log.Fatal("Error getting column names:", err)
+		return fmt.Errorf("error getting column names: %w", err)
 	}
 
 	// open csv to write to
 	file, err := os.Create(filename + ".csv")
 	if err != nil {
-		log.Fatal("Error creating csv file", err)
+		return fmt.Errorf("error creating csv file: %w", err)
 	}
 	defer file.Close()
 
@@ -67,7 +60,7 @@ func exportCSV(filename string) {
 
 	// write col headers
 	if err := writer.Write(cols); err != nil {
-		log.Fatal("Error writing CSV headers")
+		return fmt.Errorf("error writing CSV headers: %w", err)
 	}
 
 	// write rows of data
@@ -81,7 +74,7 @@ func exportCSV(filename string) {
 
 		// scan row into value ptrs
 		if err := rows.Scan(valuePtrs...); err != nil {
-			log.Fatal("Error scanning row:", err)
+			return fmt.Errorf("error scanning row: %w", err)
 		}
 
 		// convert values to string
@@ -96,43 +89,39 @@ func exportCSV(filename string) {
 
 		// write row to csv
 		if err := writer.Write(stringVals); err != nil {
-			log.Fatal("Error writing row to CSV:", err)
+			return fmt.Errorf("error writing row to CSV: %w", err)
 		}
 	}
 	log.Println("Export to CSV completed successfully")
+	return rows.Err()
 }
 
-func exportSQL(filename string) {
+func (s *Store) exportSQL(filename string) error {
 	log.Println("Exporting to SQL file")
 
-	db, err := sql.Open("sqlite3", "games.db")
-	if err != nil {
-		log.Fatal("Error opening database for copying", err)
-	}
-	defer db.Close()
-
 	// open file for writing sql dump
 	file, err := os.Create(filename + ".sql")
 	if err != nil {
-		log.Fatal("Error creating SQL (dump) file:", err)
+		return fmt.Errorf("error creating SQL (dump) file: %w", err)
 	}
 	defer file.Close()
 
 	// begin dump
 	file.WriteString("BEGIN TRANSACTION;\n")
 
-	//export schema
+	//export schema. indexes come after the tables they are on
+	// indexes sqlite makes on its own have no sql, since they are made again with their table
 	log.Println("Extracting Schema")
-	rows, err := db.Query("SELECT sql FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%';")
+	rows, err := s.db.Query("SELECT sql FROM sqlite_master WHERE type IN ('table', 'index') AND name NOT LIKE 'sqlite_%' AND sql IS NOT NULL ORDER BY type = 'index';")
 	if err != nil {
-		log.Fatal("Error retrieving schema:", err)
+		return fmt.Errorf("error retrieving schema: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var schema string
 		if err := rows.Scan(&schema); err != nil {
-			log.Fatal("Error scanning schema row:", err)
+			return fmt.Errorf("error scanning schema row: %w", err)
 		}
 		if schema != "" {
 			file.WriteString(schema + ";\n")
@@ -141,9 +130,9 @@ func exportSQL(filename string) {
 
 	//export tables
 	log.Println("Extracting table")
-	tables, err := db.Query("SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%';")
+	tables, err := s.db.Query("SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%';")
 	if err != nil {
-		log.Fatal("Error retrieving table names:", err)
+		return fmt.Errorf("error retrieving table names: %w", err)
 	}
 	defer tables.Close()
 
@@ -151,21 +140,22 @@ func exportSQL(filename string) {
 	for tables.Next() {
 		var tableName string
 		if err := tables.Scan(&tableName); err != nil {
-			log.Fatal("Error scanning table name:", err)
+			return fmt.Errorf("error scanning table name: %w", err)
 		}
 
 		// fetch all rows from the table
 		log.Println("Obtaining Row data")
-		rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s;", tableName))
+		rows, err := s.db.Query(fmt.Sprintf("SELECT * FROM %s;", tableName))
 		if err != nil {
-			log.Fatalf("Error retrieving data from %s: %v", tableName, err)
+			return fmt.Errorf("error retrieving data from %s: %w", tableName, err)
 		}
 
 		// get column names
 		cols, err := rows.Columns()
 		log.Println("Obtaining Column data")
 		if err != nil {
-			log.Fatal("Error getting columns:", err)
+			rows.Close()
+			return fmt.Errorf("error getting columns: %w", err)
 		}
 		numCols := len(cols)
 
@@ -180,7 +170,8 @@ func exportSQL(filename string) {
 		log.Println("Writing table contents")
 		for rows.Next() {
 			if err := rows.Scan(valuePtrs...); err != nil {
-				log.Fatal("Error scanning row:", err)
+				rows.Close()
+				return fmt.Errorf("error scanning row: %w", err)
 			}
 
 			// convert values to SQL format
@@ -213,30 +204,25 @@ func exportSQL(filename string) {
 	file.WriteString("COMMIT;\n")
 	log.Println("Export to SQL completed successfully.")
 
-	return
+	return nil
 }
 
 // PERF: Export the current view, not the default one in the database
-func exportMarkdown(filename string) {
+func (s *Store) exportMarkdown(filename string) error {
 	log.Println("Exporting to Markdown")
-	db, err := sql.Open("sqlite3", "games.db")
-	if err != nil {
-		log.Fatal("Error accessing local dB: ", err)
-	}
-	defer db.Close()
 
 	// select everything except the url to be grabbed
 	log.Println("Obtaining Game Data")
-	rows, err := db.Query("SELECT name, favorite, main, mainPlus, comp FROM games")
+	rows, err := s.db.Query("SELECT name, favorite, main, mainPlus, comp FROM games")
 	if err != nil {
-		log.Fatal("Error retrieving games: ", err)
+		return fmt.Errorf("error retrieving games: %w", err)
 	}
 	defer rows.Close()
 
 	// open the markdown file we are going to be writing to
 	mdfile, err := os.Create(filename + ".md")
 	if err != nil {
-		log.Fatal("Error creating markdown file", err)
+		return fmt.Errorf("error creating markdown file: %w", err)
 	}
 	defer mdfile.Close()
 
@@ -244,7 +230,7 @@ func exportMarkdown(filename string) {
 	_, err = mdfile.WriteString("| No. | **Game Name** | **Main Story** | **Main + Sides** | **Completionist** | Favorite |\n")
 	_, err = mdfile.WriteString("| :----: | :---- | ---- | ---- | ---- | ---- |\n")
 	if err != nil {
-		log.Fatal("Failed to begin writing to markdown file")
+		return fmt.Errorf("failed to begin writing to markdown file: %w", err)
 	}
 
 	id := 1
@@ -255,15 +241,19 @@ func exportMarkdown(filename string) {
 		var main, mainPlus, comp float32
 		var favorite int
 		if err := rows.Scan(&name, &favorite, &main, &mainPlus, &comp); err != nil {
-			log.Fatal("Error scanning row: ", err)
+			return fmt.Errorf("error scanning row: %w", err)
 		}
 
 		//  | No. | name | Main Story | Main + Sides | Completionist | Favorite |
 		_, err = mdfile.WriteString(fmt.Sprintf("| %d. | %s | %v | %v | %v | %d |\n", id, name, main, mainPlus, comp, favorite))
+		if err != nil {
+			return fmt.Errorf("error writing game %s to markdown file: %w", name, err)
+		}
 		id += 1
 	}
 
 	log.Println("Export to Markdown completed successfully")
+	return nil
 }
 
 // joins columns into a single string
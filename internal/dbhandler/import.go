@@ -2,42 +2,40 @@ package dbhandler
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/EZRA-DVLPR/GameList/model"
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// selector for importing
-func Import(choice int, filename string) {
+// selector for importing. cancelling ctx stops the import before the next game
+func (s *Store) Import(ctx context.Context, choice int, filename string) error {
 	switch choice {
 	case 1:
-		importCSV(filename)
+		return s.importCSV(ctx, filename)
 	case 2:
-		importSQL(filename)
+		return s.importSQL(filename)
 	case 3:
-		importTXT(filename)
+		return s.importTXT(ctx, filename)
 	default:
-		log.Fatal("No such import exists!")
+		return fmt.Errorf("%w: import %d", ErrUnknownOption, choice)
 	}
 }
 
-func importCSV(filename string) {
+func (s *Store) importCSV(ctx context.Context, filename string) error {
 	log.Println("Importing data from CSV: ", filename)
-	db, err := sql.Open("sqlite3", "games.db")
-	if err != nil {
-		log.Fatal("Error opening db:", err)
-	}
-	defer db.Close()
 
 	file, err := os.Open(filename)
 	if err != nil {
-		log.Fatal("error opening CSV:", err)
+		return fmt.Errorf("error opening CSV: %w", err)
 	}
 	defer file.Close()
 
@@ -45,30 +43,14 @@ func importCSV(filename string) {
 	reader := csv.NewReader(file)
 	rows, err := reader.ReadAll()
 	if err != nil {
-		log.Fatal("Error reading CSV:", err)
+		return fmt.Errorf("error reading CSV: %w", err)
 	}
 	if len(rows) < 1 {
-		log.Fatal("CSV file is empty or improperly formatted")
+		return fmt.Errorf("%w: %s", ErrEmptyImport, filename)
 	}
 
-	model.SetMaxProcesses(len(rows))
-	// create the table if it does not exist
-	var exists int
-	err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='games'").Scan(&exists)
-	if exists != 1 {
-		log.Println("Table does not exist. Creating table to insert data")
-		var name string
-		err = db.QueryRow("SELECT name FROM sqlite_master WHERE type='table' AND name='games'").Scan(&name)
-		if err != nil {
-			_, err := db.Exec("CREATE TABLE games (name TEXT PRIMARY KEY, hltburl TEXT, completionatorurl TEXT, favorite INTEGER, main REAL, mainPlus REAL, comp REAL)")
-			if err != nil {
-				log.Fatal("Error creating table:", err)
-			}
-			log.Println("Table created")
-		} else {
-			log.Fatal("Error with query for table creation")
-		}
-	}
+	// first row is the header, so it is not a process
+	model.SetMaxProcesses(len(rows) - 1)
 
 	// setup transaction with dummy values
 	// INSERT OR REPLACE INTO GAMES [colname], [colname], ... VALUES ?,?,...
@@ -84,74 +66,161 @@ func importCSV(filename string) {
 	)
 
 	// start transaction
-	tx, err := db.Begin()
+	tx, err := s.db.Begin()
 	if err != nil {
-		log.Fatal("Error starting transaction:", err)
+		return fmt.Errorf("error starting transaction: %w", err)
 	}
 	log.Println("Inserting data from CSV")
 	// turns `INSERT OR REPLACE INTO GAMES [colname] VALUES ?`
 	// into `INSERT OR REPLACE INTO GAMES name, ... VALUE gamename,...`
 	// and executes transaction for each row
-	for _, row := range rows[1:] {
+	// a bad row is skipped so the rest of the file still gets imported
+	var errs []error
+	for i, row := range rows[1:] {
+		// nothing from a cancelled import is kept
+		if ctx.Err() != nil {
+			log.Println("Import from CSV cancelled")
+			tx.Rollback()
+			return ctx.Err()
+		}
+
 		_, err := tx.Exec(insertStmt, convertRowToInterface(row)...)
 		if err != nil {
-			tx.Rollback()
-			log.Fatal("Error inserting data:", err)
+			log.Println("Error inserting row, skipping it:", err)
+			errs = append(errs, fmt.Errorf("error inserting CSV row %d: %w", i+2, err))
 		}
 		model.IncrementProgress()
 	}
 
+	// the file may have no name keys, or keys that no longer match edited names
+	if err := updateNameKeys(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// commit transaction
 	if err := tx.Commit(); err != nil {
-		log.Fatal("Error committing transaction:", err)
+		return fmt.Errorf("error committing transaction: %w", err)
 	}
 
-	log.Println("Import from CSV completed successfully")
+	log.Println("Import from CSV completed")
+	return errors.Join(errs...)
 }
 
-func importSQL(filename string) {
+func (s *Store) importSQL(filename string) error {
 	log.Println("Importing data from SQL:", filename)
-	db, err := sql.Open("sqlite3", "games.db")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
 	model.SetMaxProcesses(1)
 
-	// drop the existing tables
-	log.Println("Deleting previous data")
-	_, err = db.Exec("DROP TABLE IF EXISTS games;")
+	sqlDump, err := os.ReadFile(filename)
 	if err != nil {
-		log.Fatal("Error dropping tables:", err)
+		return fmt.Errorf("error reading SQL file: %w", err)
 	}
 
-	sqlDump, err := os.ReadFile(filename)
+	// the old tables are only dropped along with the import, so a dump that fails leaves the DB as it was
+	tx, err := s.db.Begin()
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	// drop the existing tables. the dump brings its own schema and schema version
+	log.Println("Deleting previous data")
+	if err := dropAllTables(tx); err != nil {
+		tx.Rollback()
+		return err
 	}
 
 	// perform the import (dump)
 	log.Println("Dumping SQL contents")
-	_, err = db.Exec(string(sqlDump))
-	if err != nil {
-		log.Fatal("Error importing sql database:", err)
+	if _, err := tx.Exec(withoutTransaction(string(sqlDump))); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error importing sql database: %w", err)
+	}
+
+	// a dump from a newer version could never be opened again, so it must not replace the DB
+	if err := checkDumpVersion(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	// dumps from older versions are brought up to the current schema
+	if err := s.migrate(); err != nil {
+		return err
+	}
+	if err := updateNameKeys(s.db); err != nil {
+		return err
 	}
 	model.IncrementProgress()
 
 	log.Println("SQL database imported successfully")
+	return nil
 }
 
-func importTXT(filename string) {
-	log.Println("Importing data from TXT:", filename)
-	db, err := sql.Open("sqlite3", "games.db")
+// returns ErrSchemaTooNew if the dump that was run in tx is from a newer version of GameList
+// a dump made before migrations existed has no schema_version table and is migrated after the import
+func checkDumpVersion(tx *sql.Tx) error {
+	var tables int
+	err := tx.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'").Scan(&tables)
 	if err != nil {
-		log.Fatal("Error opening db:", err)
+		return fmt.Errorf("error checking schema version of dump: %w", err)
+	}
+	if tables == 0 {
+		return nil
 	}
-	defer db.Close()
+
+	var version int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version); err != nil {
+		return fmt.Errorf("error reading schema version of dump: %w", err)
+	}
+	if latest := latestSchemaVersion(); version > latest {
+		return fmt.Errorf("%w: dump is at version %d, newest known is %d", ErrSchemaTooNew, version, latest)
+	}
+	return nil
+}
+
+// statements of a dump that start or end its own transaction. eg. "BEGIN TRANSACTION;" and "COMMIT;"
+var transactionStatement = regexp.MustCompile(`(?im)^\s*(?:BEGIN(?:\s+(?:DEFERRED|IMMEDIATE|EXCLUSIVE))?(?:\s+TRANSACTION)?|COMMIT(?:\s+TRANSACTION)?|END\s+TRANSACTION)\s*;\s*$`)
+
+// removes the statements that start and end the transaction of the dump
+// so it can be run inside the transaction of the import
+func withoutTransaction(dump string) string {
+	return transactionStatement.ReplaceAllString(dump, "")
+}
+
+// drops every table in the DB, leaving it empty for an SQL dump
+func dropAllTables(db execer) error {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%';")
+	if err != nil {
+		return fmt.Errorf("error retrieving table names: %w", err)
+	}
+
+	var tableNames []string
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning table name: %w", err)
+		}
+		tableNames = append(tableNames, tableName)
+	}
+	rows.Close()
+
+	for _, tableName := range tableNames {
+		if _, err := db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s;", tableName)); err != nil {
+			return fmt.Errorf("error dropping table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) importTXT(ctx context.Context, filename string) error {
+	log.Println("Importing data from TXT:", filename)
 
 	file, err := os.Open(filename)
 	if err != nil {
-		log.Fatal("error opening txt file:", err)
+		return fmt.Errorf("error opening txt file: %w", err)
 	}
 	defer file.Close()
 
@@ -166,10 +235,7 @@ func importTXT(filename string) {
 	// for each game in gameNames, perform search and add to DB
 	log.Println("List of game names obtained. Will now search then add each game to DB")
 	model.SetMaxProcesses(len(gameNames))
-	for _, game := range gameNames {
-		log.Println("Obtaining Data for game", game)
-		SearchAddToDB(game)
-	}
-
+	err = s.SearchAddAll(ctx, gameNames)
 	log.Println("Finished obtaining data for games in txt file")
+	return err
 }
@@ -0,0 +1,106 @@
+package dbhandler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
+)
+
+// returns the saved link to the page of the game on each source, by source name
+// a source with no link saved has an empty link
+func (s *Store) SourceURLs(gameName string) (map[string]string, error) {
+	sources := scraper.Sources()
+	urls, err := s.savedURLs(gameName, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make(map[string]string, len(sources))
+	for i, src := range sources {
+		links[src.Name()] = urls[i]
+	}
+	return links, nil
+}
+
+// binds the game to the pages given for each source by name, then updates the game from them
+// so a game matched to the wrong page can be fixed without removing it
+// an empty link removes the saved one, so the game is searched for on that source again
+// counts as one process for the progress bar like UpdateGame
+func (s *Store) SetSourceURLs(ctx context.Context, gameName string, links map[string]string) error {
+	defer countProcess(ctx)
+
+	fetched, err := s.saveSourceURLs(ctx, gameName, links)
+	if err != nil {
+		return err
+	}
+
+	// the times and details now come from the pages just saved. those already fetched are not fetched again
+	return s.updateGame(ctx, gameName, fetched)
+}
+
+// saves the links that changed once every one of them is the page of a game on its source
+// each changed link is fetched first, so nothing is saved if any of them does not load or has no times
+// returns what was fetched from each changed link by source name
+func (s *Store) saveSourceURLs(ctx context.Context, gameName string, links map[string]string) (map[string]scraper.Game, error) {
+	for name := range links {
+		if _, ok := scraper.GetSource(name); !ok {
+			return nil, fmt.Errorf("%w: source %s", ErrUnknownOption, name)
+		}
+	}
+	saved, err := s.SourceURLs(gameName)
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []string
+	var vals []any
+	fetched := map[string]scraper.Game{}
+	for _, src := range scraper.Sources() {
+		link, ok := links[src.Name()]
+		if !ok {
+			continue
+		}
+
+		link = strings.TrimSpace(link)
+		if link != "" {
+			gameURL, ok := src.GameURL(link)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s is not the page of a game on %s", ErrNotGamePage, link, src.Name())
+			}
+			link = gameURL
+		}
+		if link == saved[src.Name()] {
+			continue
+		}
+
+		if link != "" {
+			log.Printf("Checking %s link for game %s: %s\n", src.Name(), gameName, link)
+			game, err := src.Fetch(ctx, link)
+			if err != nil {
+				return nil, fmt.Errorf("error checking %s link %s: %w", src.Name(), link, err)
+			}
+			fetched[src.Name()] = game
+		}
+		sets = append(sets, urlColumn(src)+" = ?")
+		vals = append(vals, link)
+	}
+	if len(sets) == 0 {
+		return fetched, nil
+	}
+
+	res, err := s.db.Exec(
+		fmt.Sprintf("UPDATE games SET %s WHERE name = ?", join(sets, ", ")),
+		append(vals, gameName)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error saving links for game %s: %w", gameName, err)
+	}
+	if err := checkRowsAffected(res, gameName); err != nil {
+		return nil, err
+	}
+	log.Println("Saved new links for game:", gameName)
+	return fetched, nil
+}
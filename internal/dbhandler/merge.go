@@ -0,0 +1,127 @@
+package dbhandler
+
+import (
+	"log"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
+	"github.com/EZRA-DVLPR/GameList/model"
+)
+
+// strategies for combining the times of several sources into the single value saved in the games table
+// the chosen one is saved in the merge strategy preference
+const (
+	MergeMax      = "max"
+	MergeMin      = "min"
+	MergeMean     = "mean"
+	MergeMedian   = "median"
+	MergeWeighted = "weighted" // mean weighted by the number of submissions each source reports, o/w the mean
+
+	// followed by the name of a source. eg. "prefer:HLTB"
+	mergePreferPrefix = "prefer:"
+)
+
+// strategy that takes the time of the source when it has one, o/w the highest of the others
+func MergePrefer(sourceName string) string {
+	return mergePreferPrefix + sourceName
+}
+
+// returns the name of the preferred source if the strategy is a prefer strategy
+func PreferredSource(strategy string) (sourceName string, ok bool) {
+	return strings.CutPrefix(strategy, mergePreferPrefix)
+}
+
+// every strategy that can be chosen, with a prefer strategy for each registered source
+func MergeStrategies() []string {
+	strategies := []string{MergeMax, MergeMin, MergeMean, MergeMedian, MergeWeighted}
+	for _, sourceName := range scraper.SourceNames() {
+		strategies = append(strategies, MergePrefer(sourceName))
+	}
+	return strategies
+}
+
+// a single time reported by a source
+type sourceValue struct {
+	source string
+	value  float32
+	polled int
+}
+
+// returns the merge strategy preference, defaulting to the highest value
+func currentMergeStrategy() string {
+	strategy, _ := model.GetMergeStrategy()
+	if strategy == "" {
+		return MergeMax
+	}
+	return strategy
+}
+
+// combines the values of the sources into one with the given strategy
+// returns -1 if there are no values
+func mergeValues(strategy string, values []sourceValue) float32 {
+	if len(values) == 0 {
+		return -1
+	}
+
+	if preferred, ok := PreferredSource(strategy); ok {
+		for _, v := range values {
+			if v.source == preferred {
+				return v.value
+			}
+		}
+		// preferred source has no time for this category so fall back to the highest
+		return mergeValues(MergeMax, values)
+	}
+
+	nums := make([]float64, len(values))
+	for i, v := range values {
+		nums[i] = float64(v.value)
+	}
+
+	switch strategy {
+	case MergeMax:
+		return float32(slices.Max(nums))
+
+	case MergeMin:
+		return float32(slices.Min(nums))
+
+	case MergeMean:
+		var sum float64
+		for _, n := range nums {
+			sum += n
+		}
+		return roundTime(sum / float64(len(nums)))
+
+	case MergeMedian:
+		slices.Sort(nums)
+		mid := len(nums) / 2
+		if len(nums)%2 == 1 {
+			return float32(nums[mid])
+		}
+		return roundTime((nums[mid-1] + nums[mid]) / 2)
+
+	case MergeWeighted:
+		// a source that does not report how many submissions it has cannot be weighed against the others
+		// so every source counts the same. o/w its time would be left out
+		if slices.ContainsFunc(values, func(v sourceValue) bool { return v.polled <= 0 }) {
+			return mergeValues(MergeMean, values)
+		}
+		var sum, weights float64
+		for _, v := range values {
+			sum += float64(v.value) * float64(v.polled)
+			weights += float64(v.polled)
+		}
+		return roundTime(sum / weights)
+
+	default:
+		log.Println("Unknown merge strategy. Taking the highest value:", strategy)
+		return mergeValues(MergeMax, values)
+	}
+}
+
+// rounds computed times to 2 decimal places so they display cleanly
+func roundTime(time float64) float32 {
+	return float32(math.Round(time*100) / 100)
+}
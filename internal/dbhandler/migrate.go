@@ -0,0 +1,295 @@
+package dbhandler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/EZRA-DVLPR/GameList/internal/normalize"
+)
+
+// the DB file was last migrated by a newer build that knows about more migrations than this one
+// refuse to touch it rather than risk corrupting data we do not understand
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of GameList")
+
+// a single change to the schema. version is the schema version once up has run
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// every migration in the order they are applied
+// INFO: only ever append to this list. never edit or reorder a migration that has shipped
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create games table",
+		up: func(tx *sql.Tx) error {
+			// IF NOT EXISTS so DBs made before migrations existed are taken as version 1
+			_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS games (
+				name TEXT PRIMARY KEY,
+				hltburl TEXT,
+				completionatorurl TEXT,
+				favorite INTEGER,
+				main REAL,
+				mainPlus REAL,
+				comp REAL
+			);
+			`)
+			return err
+		},
+	},
+	{
+		version:     2,
+		description: "create game_times table holding the times from each source",
+		up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+			CREATE TABLE game_times (
+				name TEXT NOT NULL,
+				source TEXT NOT NULL,
+				category TEXT NOT NULL,
+				value REAL NOT NULL,
+				fetchedAt TIMESTAMP NOT NULL,
+				PRIMARY KEY (name, source, category)
+			);
+			`)
+			return err
+		},
+	},
+	{
+		version:     3,
+		description: "add number of submissions to game_times",
+		up: func(tx *sql.Tx) error {
+			_, err := tx.Exec("ALTER TABLE game_times ADD COLUMN polled INTEGER NOT NULL DEFAULT 0;")
+			return err
+		},
+	},
+	{
+		version:     4,
+		description: "create game_aliases table mapping store names to search names or links",
+		up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+			CREATE TABLE game_aliases (
+				aliasKey TEXT PRIMARY KEY,
+				alias TEXT NOT NULL,
+				searchName TEXT NOT NULL DEFAULT '',
+				hltburl TEXT NOT NULL DEFAULT '',
+				completionatorurl TEXT NOT NULL DEFAULT ''
+			);
+			`)
+			return err
+		},
+	},
+	{
+		version:     5,
+		description: "add the time as shown by the source to game_times",
+		up: func(tx *sql.Tx) error {
+			_, err := tx.Exec("ALTER TABLE game_times ADD COLUMN raw TEXT NOT NULL DEFAULT '';")
+			return err
+		},
+	},
+	{
+		version:     6,
+		description: "add release date, platforms, genres, developer, publisher and cover to games",
+		up: func(tx *sql.Tx) error {
+			for _, col := range []string{"releaseDate", "platforms", "genres", "developer", "publisher", "coverURL"} {
+				_, err := tx.Exec(fmt.Sprintf("ALTER TABLE games ADD COLUMN %s TEXT NOT NULL DEFAULT '';", col))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version:     7,
+		description: "add the spread of the submitted times to game_times",
+		up: func(tx *sql.Tx) error {
+			for _, col := range []string{"low", "median", "high"} {
+				_, err := tx.Exec(fmt.Sprintf("ALTER TABLE game_times ADD COLUMN %s REAL NOT NULL DEFAULT -1;", col))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version:     8,
+		description: "add game_platform_times table and the platform each game is owned on",
+		up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+			CREATE TABLE game_platform_times (
+				name TEXT NOT NULL,
+				source TEXT NOT NULL,
+				platform TEXT NOT NULL,
+				polled INTEGER NOT NULL DEFAULT 0,
+				main REAL NOT NULL DEFAULT -1,
+				mainPlus REAL NOT NULL DEFAULT -1,
+				comp REAL NOT NULL DEFAULT -1,
+				PRIMARY KEY (name, source, platform)
+			);
+			`)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec("ALTER TABLE games ADD COLUMN myPlatform TEXT NOT NULL DEFAULT '';")
+			return err
+		},
+	},
+	{
+		version:     9,
+		description: "add co-op, versus and speedrun times to games",
+		up: func(tx *sql.Tx) error {
+			for _, col := range []string{"coop", "vs", "speedrun"} {
+				_, err := tx.Exec(fmt.Sprintf("ALTER TABLE games ADD COLUMN %s REAL NOT NULL DEFAULT -1;", col))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version:     10,
+		description: "add indexed name key to games and make name keys only ignore case, accents and punctuation",
+		up: func(tx *sql.Tx) error {
+			_, err := tx.Exec("ALTER TABLE games ADD COLUMN nameKey TEXT NOT NULL DEFAULT '';")
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec("CREATE INDEX games_nameKey ON games (nameKey);")
+			if err != nil {
+				return err
+			}
+			if err := updateNameKeys(tx); err != nil {
+				return err
+			}
+
+			// aliases were saved with a key that also dropped editions, so they are keyed again
+			return rekeyAliases(tx)
+		},
+	},
+	{
+		version:     11,
+		description: "make roman numerals in name keys numbers",
+		up: func(tx *sql.Tx) error {
+			if err := updateNameKeys(tx); err != nil {
+				return err
+			}
+			return rekeyAliases(tx)
+		},
+	},
+}
+
+// saves every alias again under the current key of its name
+// every alias is read before any is written back, so a new key never clashes with an old one
+func rekeyAliases(tx *sql.Tx) error {
+	type alias struct{ alias, searchName, hltburl, completionatorurl string }
+	rows, err := tx.Query("SELECT alias, searchName, hltburl, completionatorurl FROM game_aliases")
+	if err != nil {
+		return err
+	}
+	var aliases []alias
+	for rows.Next() {
+		var a alias
+		if err := rows.Scan(&a.alias, &a.searchName, &a.hltburl, &a.completionatorurl); err != nil {
+			rows.Close()
+			return err
+		}
+		aliases = append(aliases, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM game_aliases"); err != nil {
+		return err
+	}
+	for _, a := range aliases {
+		_, err := tx.Exec(
+			"INSERT OR REPLACE INTO game_aliases (aliasKey, alias, searchName, hltburl, completionatorurl) VALUES (?,?,?,?,?)",
+			normalize.Key(a.alias), a.alias, a.searchName, a.hltburl, a.completionatorurl,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the version the schema is at once every known migration has run
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// brings the schema up to date, running each migration newer than the stored version in its own transaction
+func (s *Store) migrate() error {
+	_, err := s.db.Exec("CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL);")
+	if err != nil {
+		return fmt.Errorf("error creating schema_version table: %w", err)
+	}
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	latest := latestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("%w: database is at version %d, newest known is %d", ErrSchemaTooNew, current, latest)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		log.Printf("Migrating DB to version %d: %s\n", m.version, m.description)
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("error starting migration %d: %w", m.version, err)
+		}
+		if err := m.up(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error running migration %d (%s): %w", m.version, m.description, err)
+		}
+		if err := setSchemaVersion(tx, m.version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing migration %d: %w", m.version, err)
+		}
+	}
+
+	if current < latest {
+		log.Println("DB migrated to schema version:", latest)
+	}
+	return nil
+}
+
+// returns the version stored in the DB. 0 means no migrations have run yet
+func (s *Store) SchemaVersion() (version int, err error) {
+	err = s.db.QueryRow("SELECT version FROM schema_version LIMIT 1").Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("error reading schema version: %w", err)
+	}
+	return version, nil
+}
+
+// replaces the stored version with the given one
+func setSchemaVersion(tx *sql.Tx, version int) error {
+	if _, err := tx.Exec("DELETE FROM schema_version"); err != nil {
+		return fmt.Errorf("error clearing schema version: %w", err)
+	}
+	if _, err := tx.Exec("INSERT INTO schema_version (version) VALUES (?)", version); err != nil {
+		return fmt.Errorf("error saving schema version %d: %w", version, err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package dbhandler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
+)
+
+// the times of a game on one platform as reported by a single source
+type PlatformTime struct {
+	Source string
+	scraper.PlatformTime
+}
+
+// replaces the saved platform times of the source with those it just reported
+func savePlatformTimes(tx *sql.Tx, gameName string, res sourceResult) error {
+	_, err := tx.Exec("DELETE FROM game_platform_times WHERE name = ? AND source = ?", gameName, res.source.Name())
+	if err != nil {
+		return fmt.Errorf("error clearing %s platform times for game %s: %w", res.source.Name(), gameName, err)
+	}
+
+	for _, pt := range res.game.PlatformTimes {
+		_, err := tx.Exec(
+			"INSERT OR REPLACE INTO game_platform_times (name, source, platform, polled, main, mainPlus, comp) VALUES (?,?,?,?,?,?,?)",
+			gameName,
+			res.source.Name(),
+			pt.Platform,
+			pt.Polled,
+			pt.Main,
+			pt.MainPlus,
+			pt.Comp,
+		)
+		if err != nil {
+			return fmt.Errorf("error saving %s platform times for game %s: %w", res.source.Name(), gameName, err)
+		}
+	}
+	return nil
+}
+
+// returns every saved platform time of the game from every source, most submissions first
+func (s *Store) PlatformTimes(gameName string) (times []PlatformTime, err error) {
+	rows, err := s.db.Query(
+		"SELECT source, platform, polled, main, mainPlus, comp FROM game_platform_times WHERE name = ? ORDER BY polled DESC, platform",
+		gameName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error obtaining platform times for game %s: %w", gameName, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pt PlatformTime
+		if err := rows.Scan(&pt.Source, &pt.Platform, &pt.Polled, &pt.Main, &pt.MainPlus, &pt.Comp); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		times = append(times, pt)
+	}
+	return times, rows.Err()
+}
+
+// returns the platform the game is owned on. empty if it has not been set
+func (s *Store) MyPlatform(gameName string) (platform string, err error) {
+	err = s.db.QueryRow("SELECT myPlatform FROM games WHERE name = ?", gameName).Scan(&platform)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: %s", ErrGameNotFound, gameName)
+	} else if err != nil {
+		return "", fmt.Errorf("error obtaining platform of game %s: %w", gameName, err)
+	}
+	return platform, nil
+}
+
+// sets the platform the game is owned on so the table shows the times on that platform
+// the platform is matched to the start of the platforms a source reports, so "PlayStation" covers "PlayStation 4" and "PlayStation 5"
+// an empty platform shows the times across every platform again
+func (s *Store) SetMyPlatform(gameName string, platform string) error {
+	res, err := s.db.Exec("UPDATE games SET myPlatform = ? WHERE name = ?", platform, gameName)
+	if err != nil {
+		return fmt.Errorf("error setting platform of game %s: %w", gameName, err)
+	}
+	if err := checkRowsAffected(res, gameName); err != nil {
+		return err
+	}
+	log.Println("Platform of game", gameName, "set to:", platform)
+	return nil
+}
+
+// searches and adds every game in gameNames to the DB like SearchAddAll, and sets the platform they are owned on
+// games already in the DB also get the platform unless one was already set for them
+func (s *Store) SearchAddAllOn(ctx context.Context, gameNames []string, platform string) error {
+	return runBatch(ctx, gameNames, func(ctx context.Context, gameName string) error {
+		savedName, err := s.searchAdd(ctx, gameName)
+		if err != nil && !errors.Is(err, ErrDuplicateGame) {
+			return err
+		}
+
+		// a single source saves the game under the name on the site, which may not be the same game as the name of the store
+		if perr := s.setImportedPlatform(savedName, platform); perr != nil {
+			return errors.Join(err, perr)
+		}
+		return err
+	})
+}
+
+// sets the platform of the saved game that is the same game as gameName, unless it already has one
+func (s *Store) setImportedPlatform(gameName string, platform string) error {
+	existing, found, err := s.findSameGame(gameName)
+	if err != nil || !found {
+		return err
+	}
+	_, err = s.db.Exec("UPDATE games SET myPlatform = ? WHERE name = ? AND myPlatform = ''", platform, existing)
+	if err != nil {
+		return fmt.Errorf("error setting platform of game %s: %w", existing, err)
+	}
+	return nil
+}
+
+// an expression giving the time of the category on the platform the game is owned on
+// the platform reported by the most players is used when several match. o/w the time across every platform
+func platformTimeColumn(category string) string {
+	return fmt.Sprintf(`COALESCE((
+				SELECT p.%[1]s FROM game_platform_times p
+				WHERE p.name = games.name AND games.myPlatform != '' AND p.%[1]s > 0
+				AND p.platform LIKE games.myPlatform || '%%'
+				ORDER BY p.polled DESC LIMIT 1
+			), games.%[1]s) AS %[1]s`, category)
+}
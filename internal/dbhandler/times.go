@@ -0,0 +1,227 @@
+package dbhandler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
+)
+
+// a completion time for a game as reported by a single source
+type GameTime struct {
+	Source    string
+	Category  string
+	Value     float32
+	Polled    int    // 0 when the source does not report the number of submissions
+	Raw       string // the time as the source showed it. empty for times saved before it was recorded
+	FetchedAt time.Time
+
+	// spread of the submitted times. -1 when the source does not report it
+	Low, Median, High float32
+}
+
+// replaces the saved times of each source in results with the newly fetched ones
+// categories a source has no time for are removed rather than saved as -1
+func (s *Store) saveSourceTimes(gameName string, results []sourceResult) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction for source times: %w", err)
+	}
+
+	fetchedAt := time.Now()
+	for _, res := range results {
+		_, err := tx.Exec("DELETE FROM game_times WHERE name = ? AND source = ?", gameName, res.source.Name())
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error clearing %s times for game %s: %w", res.source.Name(), gameName, err)
+		}
+		if err := savePlatformTimes(tx, gameName, res); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		for _, category := range scraper.Categories {
+			value := res.game.Time(category)
+			if value <= 0 {
+				continue
+			}
+			spread, ok := res.game.Ranges[category]
+			if !ok {
+				spread = scraper.TimeRange{Low: -1, Median: -1, High: -1}
+			}
+			_, err := tx.Exec(
+				"INSERT INTO game_times (name, source, category, value, polled, raw, fetchedAt, low, median, high) VALUES (?,?,?,?,?,?,?,?,?,?)",
+				gameName,
+				res.source.Name(),
+				category,
+				value,
+				res.game.Polled[category],
+				res.game.Raw[category],
+				fetchedAt,
+				spread.Low,
+				spread.Median,
+				spread.High,
+			)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("error saving %s times for game %s: %w", res.source.Name(), gameName, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing source times for game %s: %w", gameName, err)
+	}
+	return nil
+}
+
+// returns every saved time of the game from every source
+func (s *Store) GameTimes(gameName string) (times []GameTime, err error) {
+	rows, err := s.db.Query(
+		"SELECT source, category, value, polled, raw, fetchedAt, low, median, high FROM game_times WHERE name = ? ORDER BY source",
+		gameName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error obtaining source times for game %s: %w", gameName, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var gt GameTime
+		if err := rows.Scan(&gt.Source, &gt.Category, &gt.Value, &gt.Polled, &gt.Raw, &gt.FetchedAt, &gt.Low, &gt.Median, &gt.High); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		times = append(times, gt)
+	}
+	return times, rows.Err()
+}
+
+// recomputes the merged times in the games table from the saved times of each source
+// used when the merge strategy changes so nothing has to be scraped again
+// games that have no saved source times (eg. manual entries) are left as they are
+func (s *Store) RecomputeMergedTimes() error {
+	rows, err := s.db.Query("SELECT DISTINCT name FROM game_times")
+	if err != nil {
+		return fmt.Errorf("error obtaining games with source times: %w", err)
+	}
+	var gameNames []string
+	for rows.Next() {
+		var gameName string
+		if err := rows.Scan(&gameName); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning row: %w", err)
+		}
+		gameNames = append(gameNames, gameName)
+	}
+	rows.Close()
+
+	log.Println("Recomputing merged times for games:", len(gameNames))
+	var errs []error
+	for _, gameName := range gameNames {
+		if err := s.recomputeMergedTime(gameName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// merges the saved source times of one game with the current merge strategy and writes them to the games table
+func (s *Store) recomputeMergedTime(gameName string) error {
+	times, err := s.GameTimes(gameName)
+	if err != nil {
+		return err
+	}
+
+	results := sourceResultsFromTimes(times)
+	if len(results) == 0 {
+		return nil
+	}
+	merged := compareGetGameData(results)
+
+	var sets []string
+	var vals []any
+	for _, category := range scraper.Categories {
+		sets = append(sets, category+" = ?")
+		vals = append(vals, merged.Time(category))
+	}
+	res, err := s.db.Exec(
+		fmt.Sprintf("UPDATE games SET %s WHERE name = ?", join(sets, ", ")),
+		append(vals, gameName)...,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating merged times for game %s: %w", gameName, err)
+	}
+	return checkRowsAffected(res, gameName)
+}
+
+// rebuilds a game per source from saved times. times from sources no longer registered are skipped
+func sourceResultsFromTimes(times []GameTime) (results []sourceResult) {
+	index := map[string]int{}
+	for _, gt := range times {
+		i, ok := index[gt.Source]
+		if !ok {
+			src, registered := scraper.GetSource(gt.Source)
+			if !registered {
+				continue
+			}
+			i = len(results)
+			index[gt.Source] = i
+			game := scraper.Game{Polled: map[string]int{}, Raw: map[string]string{}, Ranges: map[string]scraper.TimeRange{}}
+			for _, category := range scraper.Categories {
+				game.SetTime(category, -1)
+			}
+			results = append(results, sourceResult{source: src, game: game})
+		}
+		results[i].game.SetTime(gt.Category, gt.Value)
+		results[i].game.Polled[gt.Category] = gt.Polled
+		results[i].game.Raw[gt.Category] = gt.Raw
+		results[i].game.Ranges[gt.Category] = scraper.TimeRange{Low: gt.Low, Median: gt.Median, High: gt.High}
+	}
+	return
+}
+
+// removes the saved source times of the game
+func (s *Store) deleteSourceTimes(gameName string) error {
+	if _, err := s.db.Exec("DELETE FROM game_times WHERE name = ?", gameName); err != nil {
+		return fmt.Errorf("error deleting source times for game %s: %w", gameName, err)
+	}
+	if _, err := s.db.Exec("DELETE FROM game_platform_times WHERE name = ?", gameName); err != nil {
+		return fmt.Errorf("error deleting platform times for game %s: %w", gameName, err)
+	}
+	return nil
+}
+
+// games whose time for a category was submitted by fewer than this many players are flagged in the table
+// sources that do not report the number of submissions are not counted
+const LowConfidencePolled = 10
+
+// added to a time in the table when it is from few submissions
+const LowConfidenceMark = " (few polled)"
+
+// returns the categories of each game whose saved times come from fewer than LowConfidencePolled submissions
+// eg. lowConfidence["Celeste"]["comp"] is true if only 3 players submitted a completionist time
+func (s *Store) lowConfidenceTimes() (map[string]map[string]bool, error) {
+	rows, err := s.db.Query(
+		"SELECT name, category FROM game_times WHERE polled > 0 GROUP BY name, category HAVING SUM(polled) < ?",
+		LowConfidencePolled,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error obtaining number of submissions: %w", err)
+	}
+	defer rows.Close()
+
+	lowConfidence := map[string]map[string]bool{}
+	for rows.Next() {
+		var gameName, category string
+		if err := rows.Scan(&gameName, &category); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		if lowConfidence[gameName] == nil {
+			lowConfidence[gameName] = map[string]bool{}
+		}
+		lowConfidence[gameName][category] = true
+	}
+	return lowConfidence, rows.Err()
+}
@@ -1,7 +1,9 @@
 package integration
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 
 	"github.com/EZRA-DVLPR/GameList/internal/dbhandler"
@@ -26,20 +28,23 @@ type EPICGame struct {
 	Logo            any    `json:"-"`
 }
 
-func GetAllGamesEpicString(input string) {
+func GetAllGamesEpicString(ctx context.Context, store *dbhandler.Store, input string) error {
 	log.Println("Getting products from Epic Games string")
 
 	var epicpage EPICPage
 	err := json.Unmarshal([]byte(input), &epicpage)
 	if err != nil {
-		log.Fatal("Error decoding json", err)
+		return fmt.Errorf("%w: error decoding Epic json: %v", ErrBadInput, err)
 	}
 
 	log.Println("Reading json input from Epic Games")
 	model.SetMaxProcesses(len(epicpage.Data.Applications))
+	var gameList []string
 	for _, app := range epicpage.Data.Applications {
 		log.Println("Game found:", app.ApplicationName)
-		dbhandler.SearchAddToDB(app.ApplicationName)
+		gameList = append(gameList, app.ApplicationName)
 	}
+	err = addAllGames(ctx, store, gameList, platformPC)
 	log.Println("Finished adding game data from Epic Games")
+	return err
 }
@@ -0,0 +1,12 @@
+package integration
+
+import "errors"
+
+// errors returned by the importers. compare with errors.Is since they are wrapped with more detail
+var (
+	// the JSON, cookie, or profile given by the user could not be used
+	ErrBadInput = errors.New("invalid integration input")
+
+	// the store page could not be reached or returned an error
+	ErrSourceUnavailable = errors.New("integration source unavailable")
+)
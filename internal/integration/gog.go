@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -52,19 +53,20 @@ type GOGProduct struct {
 	IsHidden             any    `json:"-"`
 }
 
-func GetAllGamesGOG(cookie string) {
+func GetAllGamesGOG(ctx context.Context, store *dbhandler.Store, cookie string) error {
 	log.Println("Getting products from GOG")
 
 	log.Println("Setting up HTTP request")
 	client := &http.Client{}
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		"GET",
 		"https://embed.gog.com/account/getFilteredProducts?mediaType=1&page=1",
 		nil,
 	)
 	if err != nil {
 		log.Println("Error creating request:", err)
-		return
+		return fmt.Errorf("error creating GOG request: %w", err)
 	}
 
 	// TEST: gog_us is the name of the cookie i have (US)
@@ -75,26 +77,9 @@ func GetAllGamesGOG(cookie string) {
 	req.Header.Set("Referer", "https://embed.gog.com/")
 	req.Header.Set("X-Requested-With", "XMLHttpRequest") // tells server it's an AJAX request
 
-	log.Println("Sending HTTP request")
-	resp, err := client.Do(req)
+	gogpage, err := sendGOGRequest(client, req)
 	if err != nil {
-		log.Println("Error:", err)
-		return
-	}
-	defer resp.Body.Close()
-
-	log.Println("HTTP Request processed successfully. Reading Response")
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Println("Error reading body:", err)
-		return
-	}
-
-	// grab the data from the page and parse using json
-	var gogpage GOGPage
-	err = json.Unmarshal(body, &gogpage)
-	if err != nil {
-		log.Fatal("Error decoding JSON:", err)
+		return err
 	}
 
 	// from the 1st page, get the list of game titles
@@ -109,30 +94,32 @@ func GetAllGamesGOG(cookie string) {
 	for i := 2; i <= gogpage.TotalPages; i++ {
 		// unpack the elts from the search from each page and append to gameList
 		log.Println(fmt.Sprintf("Obtaining list of game titles from page %d", i))
-		gameList = append(gameList, getGOGGames(i, cookie)...)
+		pageList, err := getGOGGames(ctx, i, cookie)
+		if err != nil {
+			return err
+		}
+		gameList = append(gameList, pageList...)
 		log.Println(fmt.Sprintf("Obtained list of game titles from page %d", i))
 	}
 
 	log.Println("All games from all pages obtained")
 	// we now have the entire list of games
 	model.SetMaxProcesses(len(gameList))
-	for _, game := range gameList {
-		log.Println("Game found:", game)
-		dbhandler.SearchAddToDB(game)
-	}
+	err = addAllGames(ctx, store, gameList, platformPC)
 	log.Println("Finished adding game data from GOG")
+	return err
 }
 
-func getGOGGames(pagenumber int, cookie string) (gameList []string) {
+func getGOGGames(ctx context.Context, pagenumber int, cookie string) (gameList []string, err error) {
 	log.Println("Setting up HTTP request")
 	client := &http.Client{}
-	req, err := http.NewRequest("GET", fmt.Sprintf(
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf(
 		"https://embed.gog.com/account/getFilteredProducts?mediaType=1&page=%d",
 		pagenumber,
 	), nil)
 	if err != nil {
 		log.Println("Error creating request:", err)
-		return
+		return nil, fmt.Errorf("error creating GOG request: %w", err)
 	}
 
 	// TEST: See above test
@@ -143,11 +130,30 @@ func getGOGGames(pagenumber int, cookie string) (gameList []string) {
 	req.Header.Set("Referer", "https://embed.gog.com/")
 	req.Header.Set("X-Requested-With", "XMLHttpRequest")
 
+	gogpage, err := sendGOGRequest(client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// from the current page, get the list of game titles
+	log.Println("Obtaining list of game titles from page 1")
+	for _, gogproduct := range gogpage.Products {
+		gameList = append(gameList, gogproduct.Title)
+	}
+	log.Println("Obtained all game titles from page:", pagenumber)
+	return gameList, nil
+}
+
+// sends the request for a page of owned products and decodes it
+func sendGOGRequest(client *http.Client, req *http.Request) (gogpage GOGPage, err error) {
 	log.Println("Sending HTTP request")
 	resp, err := client.Do(req)
 	if err != nil {
 		log.Println("Error:", err)
-		return
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return gogpage, ctxErr
+		}
+		return gogpage, fmt.Errorf("%w: %v", ErrSourceUnavailable, err)
 	}
 	defer resp.Body.Close()
 
@@ -155,21 +161,14 @@ func getGOGGames(pagenumber int, cookie string) (gameList []string) {
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		log.Println("Error reading body:", err)
-		return
+		return gogpage, fmt.Errorf("%w: %v", ErrSourceUnavailable, err)
 	}
 
 	// grab the data from the page and parse using json
-	var gogpage GOGPage
+	// an expired or wrong cookie gets a page that is not the expected json
 	err = json.Unmarshal(body, &gogpage)
 	if err != nil {
-		log.Fatal("Error decoding JSON:", err)
-	}
-
-	// from the current page, get the list of game titles
-	log.Println("Obtaining list of game titles from page 1")
-	for _, gogproduct := range gogpage.Products {
-		gameList = append(gameList, gogproduct.Title)
+		return gogpage, fmt.Errorf("%w: error decoding GOG JSON: %v", ErrBadInput, err)
 	}
-	log.Println("Obtained all game titles from page:", pagenumber)
-	return
+	return gogpage, nil
 }
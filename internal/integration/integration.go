@@ -0,0 +1,23 @@
+package integration
+
+import (
+	"context"
+	"log"
+
+	"github.com/EZRA-DVLPR/GameList/internal/dbhandler"
+)
+
+// platforms the games of each store are owned on. a platform covers every platform it is the start of
+// eg. "PlayStation" uses the times on "PlayStation 4" and "PlayStation 5"
+const (
+	platformPC          = "PC"
+	platformPlayStation = "PlayStation"
+)
+
+// searches and adds every game in gameList to the store, owned on the given platform
+// a game that fails does not stop the others. all failures are returned together
+// cancelling ctx stops before the next game. games already added are kept
+func addAllGames(ctx context.Context, store *dbhandler.Store, gameList []string, platform string) error {
+	log.Println("Adding", len(gameList), "game(s) on", platform, "from the integration")
+	return store.SearchAddAllOn(ctx, gameList, platform)
+}
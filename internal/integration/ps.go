@@ -2,6 +2,7 @@ package integration
 
 import (
 	"context"
+	"fmt"
 	"html"
 	"log"
 	"regexp"
@@ -9,23 +10,30 @@ import (
 	"strings"
 
 	"github.com/EZRA-DVLPR/GameList/internal/dbhandler"
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
 	"github.com/EZRA-DVLPR/GameList/model"
 	"github.com/chromedp/chromedp"
 )
 
-func GetAllGamesPS(profile string) {
+func GetAllGamesPS(ctx context.Context, store *dbhandler.Store, profile string) error {
 	log.Println("Getting games for PSN")
 
 	// final list holding all games from all pages
 	var gameList []string
 
 	// get games from first page, append them into gamelist, and continue grabbing until the last page
-	gamepartlist, nextpage := getAllGamesPS(profile, "1")
+	gamepartlist, nextpage, err := getAllGamesPS(ctx, profile, "1")
+	if err != nil {
+		return err
+	}
 	log.Println("Obtained all game titles from page 1")
 	for nextpage != "0" {
 		log.Println("Obtaining list of game titles from page: " + nextpage)
-		gameList = append(gameList, gamepartlist...)              // unpack and append each elt from part to gameList
-		gamepartlist, nextpage = getAllGamesPS(profile, nextpage) // get next page
+		gameList = append(gameList, gamepartlist...)                        // unpack and append each elt from part to gameList
+		gamepartlist, nextpage, err = getAllGamesPS(ctx, profile, nextpage) // get next page
+		if err != nil {
+			return err
+		}
 		log.Println("Obtained all game titles from page: ", nextpage)
 	}
 
@@ -33,37 +41,24 @@ func GetAllGamesPS(profile string) {
 	gameList = append(gameList, gamepartlist...)
 	log.Println("Obtained all game titles for profile:", profile)
 	model.SetMaxProcesses(len(gameList))
-	for _, game := range gameList {
-		dbhandler.SearchAddToDB(game)
-	}
+	err = addAllGames(ctx, store, gameList, platformPlayStation)
 	log.Println("Finished adding game data from PSN for profile:", profile)
+	return err
 }
 
-func getAllGamesPS(profile string, pagenum string) (gamelist []string, nextPageNum string) {
+func getAllGamesPS(ctx context.Context, profile string, pagenum string) (gamelist []string, nextPageNum string, err error) {
 	url := "https://psnprofiles.com/" + profile + "?ajax=1&page=" + pagenum
 
-	userAgent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
-
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.UserAgent(userAgent),
-	)
-
-	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	defer cancel()
-
-	ctx, cancel := chromedp.NewContext(allocCtx)
-	defer cancel()
-
+	// use a tab of the browser shared with the searches
 	var pageHTML string
-	err := chromedp.Run(ctx,
-		chromedp.Navigate(url),
+	err = scraper.RunBrowser(ctx, url,
 		chromedp.OuterHTML("html", &pageHTML),
 	)
+	if ctx.Err() != nil {
+		return nil, "", ctx.Err()
+	}
 	if err != nil {
-		log.Fatal(err)
+		return nil, "", fmt.Errorf("%w: %w", ErrSourceUnavailable, err)
 	}
 
 	// clean up the html unicode stuff into their respective characters
@@ -76,7 +71,12 @@ func getAllGamesPS(profile string, pagenum string) (gamelist []string, nextPageN
 		pageHTML = pageHTML[newStartIndex:]
 	}
 
-	return gamelist, getNextPage(pageHTML)
+	// a profile that does not exist has no page number to go to next
+	nextPageNum = getNextPage(pageHTML)
+	if nextPageNum == "" {
+		return nil, "", fmt.Errorf("%w: no PSN games page for profile %s", ErrBadInput, profile)
+	}
+	return gamelist, nextPageNum, nil
 }
 
 // look for the next game. if exists then true. o/w false
@@ -108,11 +108,19 @@ func getAnotherGame(pageHTML string) (gameName string, nextStartIndex int) {
 }
 
 // find NextPage and returns the value for it as a string
+// returns an empty string if there is no such value on the page
 func getNextPage(pageHTML string) (nextPage string) {
-	indexNP := strings.Index(pageHTML, "nextPage = ") + 11
-	indexEndNP := strings.Index(pageHTML[indexNP:], "\\r") + indexNP
+	indexNP := strings.Index(pageHTML, "nextPage = ")
+	if indexNP == -1 {
+		return ""
+	}
+	indexNP += 11
+	indexEndNP := strings.Index(pageHTML[indexNP:], "\\r")
+	if indexEndNP == -1 {
+		return ""
+	}
 
-	return pageHTML[indexNP:indexEndNP]
+	return pageHTML[indexNP : indexNP+indexEndNP]
 }
 
 // cleans text to remove wonky representations of characters (\u###), (&amp;####;), etc.
@@ -2,50 +2,36 @@ package integration
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/http"
 	"time"
 
 	"github.com/EZRA-DVLPR/GameList/internal/dbhandler"
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
 	"github.com/EZRA-DVLPR/GameList/model"
-	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
 )
 
-func GetAllGamesSteam(profile string, cookie string) {
+func GetAllGamesSteam(ctx context.Context, store *dbhandler.Store, profile string, cookie string) error {
 	log.Println("Getting products from Steam for given profile:", profile)
 
-	// define the cookie
-	log.Println("Setting up HTTP request")
-	cookieName := "steamLoginSecure"
-	cookieDomain := "steamcommunity.com"
-
-	ctx, cancel := chromedp.NewContext(context.Background())
-	defer cancel()
+	// the games of the profile are only listed when logged in, so the cookie of the login is sent
+	login := &http.Cookie{Name: "steamLoginSecure", Value: cookie, Domain: "steamcommunity.com"}
 
 	// timeout for 10 seconds
-	ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+	browserCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// copying the js code basically
 	gamesRootSelector := `div[data-featuretarget="gameslist-root"]`
 	gameLinksSelector := `div[data-featuretarget="gameslist-root"] div.Panel div.Panel span > a`
 
+	// use a tab of the browser shared with the searches
+	url := "https://steamcommunity.com/id/" + profile + "/games/?tab-all=&tab=all"
 	var gameNames []string
 	log.Println("Making HTTP request")
-	err := chromedp.Run(ctx,
-		// enable network to set cookies
-		network.Enable(),
-
-		// set the cookie
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			return network.SetCookie(cookieName, cookie).
-				WithDomain(cookieDomain).
-				WithPath("/").
-				Do(ctx)
-		}),
-
-		chromedp.Navigate("https://steamcommunity.com/id/"+profile+"/games/?tab-all=&tab=all"),
-
+	err := scraper.RunBrowserWithCookies(browserCtx, url, []*http.Cookie{login},
 		// wait for js to load the games list (5 seconds)
 		chromedp.Sleep(5*time.Second),
 
@@ -54,15 +40,24 @@ func GetAllGamesSteam(profile string, cookie string) {
 
 		// extract games
 		chromedp.Evaluate(`Array.from(document.querySelectorAll('`+gameLinksSelector+`')).map(el => el.textContent.trim())`, &gameNames))
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
-		log.Fatal("Failed to fetch game names:", err)
+		return fmt.Errorf("%w: failed to fetch Steam game names: %w", ErrSourceUnavailable, err)
+	}
+
+	// steam shows an empty list rather than an error when the cookie has expired
+	if len(gameNames) == 0 {
+		return fmt.Errorf("%w: no Steam games found for profile %s. the steamLoginSecure cookie may have expired", ErrBadInput, profile)
 	}
 	log.Println("HTTP Request processed successfully. List of games obtained")
 
 	model.SetMaxProcesses(len(gameNames))
 	for _, name := range gameNames {
 		log.Println("Game found:", name)
-		dbhandler.SearchAddToDB(name)
 	}
+	err = addAllGames(ctx, store, gameNames, platformPC)
 	log.Println("Finished adding game data from Steam")
+	return err
 }
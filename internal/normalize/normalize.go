@@ -0,0 +1,203 @@
+// Package normalize cleans up game names from stores and users so they can be searched for
+// and compared with the names that the sites use.
+package normalize
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// symbols that stores add to names but the sites leave out
+var symbols = strings.NewReplacer("™", "", "®", "", "©", "", "℠", "")
+
+// words that only say which edition or release of a game it is
+// words that are also part of the names of games are left out. eg. "Pokémon Gold Version", "Dark Souls Remastered"
+const editionWords = `game of the year|goty|definitive|complete|deluxe|digital deluxe|standard|ultimate|special|` +
+	`anniversary|collector'?s|enhanced|premium|launch|day one|director'?s`
+
+// platforms that stores add to names
+const platformWords = `ps[1-5]|ps vita|playstation ?[1-5]?|xbox one|xbox series x\|s|xbox series|nintendo switch|pc|windows( 10)?`
+
+// "Switch" on its own also ends names of games, so it is only removed in brackets or after a dash. eg. "Bait and Switch"
+const consoleWords = platformWords + `|switch`
+
+var (
+	// text in brackets that only names an edition or platform. eg. "(PS4)", "[Deluxe Edition]"
+	bracketed = regexp.MustCompile(`(?i)\s*[\(\[][^\)\]]*\b(?:edition|` + editionWords + `|` + consoleWords + `)\b[^\)\]]*[\)\]]`)
+
+	// suffixes removed from the end of the name until none are left
+	suffixes = []*regexp.Regexp{
+		// eg. "Game of the Year Edition", "Director's Cut"
+		regexp.MustCompile(`(?i)[\s:,\-–—]*\b(?:` + editionWords + `)\s+(?:edition|cut|version)$`),
+		// eg. "GOTY". a lone "Edition" is kept since the word before it may be part of the name
+		regexp.MustCompile(`(?i)[\s:,\-–—]*\bgoty$`),
+		// eg. "PS4 & PS5", "for PC"
+		regexp.MustCompile(`(?i)[\s:,\-–—]*(?:\bfor\s+)?\b(?:` + platformWords + `)(?:\s*(?:&|and|/|,)\s*(?:` + platformWords + `))*$`),
+		// eg. " - Switch", " - PS4 & Switch"
+		regexp.MustCompile(`(?i)\s*[\-–—]\s*(?:for\s+)?\b(?:` + consoleWords + `)(?:\s*(?:&|and|/|,)\s*(?:` + consoleWords + `))*$`),
+	}
+
+	spaces = regexp.MustCompile(`\s+`)
+)
+
+// cleans a name from a store or a user so that it can be searched for
+// symbols, editions and platforms are removed. eg. "DOOM™ Eternal - Deluxe Edition (PS4 & PS5)" -> "DOOM Eternal"
+// the name is returned as it was if nothing would be left of it
+func Query(name string) string {
+	query := symbols.Replace(name)
+	query = bracketed.ReplaceAllString(query, "")
+
+	for changed := true; changed; {
+		changed = false
+		for _, suffix := range suffixes {
+			if trimmed := suffix.ReplaceAllString(query, ""); trimmed != query && strings.TrimSpace(trimmed) != "" {
+				query = trimmed
+				changed = true
+			}
+		}
+	}
+
+	query = strings.TrimSpace(spaces.ReplaceAllString(query, " "))
+	query = strings.TrimRight(query, " :,-–—")
+	if query == "" {
+		return strings.TrimSpace(name)
+	}
+	return query
+}
+
+// words of the cleaned name in a form that can be compared
+// case and accents are ignored, punctuation is removed and roman numerals become numbers
+// eg. "Pokémon: Let's Go, Pikachu!" -> ["pokemon", "lets", "go", "pikachu"]
+func Tokens(name string) []string {
+	text := strings.NewReplacer("&", " and ").Replace(Query(name))
+	tokens := words(text)
+	for i, token := range tokens {
+		if number, ok := romanToNumber(token); ok {
+			tokens[i] = number
+		}
+	}
+	return tokens
+}
+
+// the form of the name that two names of the same game share
+// only case, accents and punctuation are ignored and roman numerals up to 20 become numbers, so editions still tell games apart
+// eg. "DOOM™ Eternal" and "Doom Eternal" have the same key, but "Dark Souls Remastered" and "Dark Souls" do not
+// a lone "X" is kept as it is, since it is also a letter. eg. "Mega Man X" is not "Mega Man 10"
+func Key(name string) string {
+	keyWords := words(name)
+	for i, word := range keyWords {
+		if value, ok := romanValue(word); ok && value <= 20 && word != "x" {
+			keyWords[i] = strconv.Itoa(value)
+		}
+	}
+	return strings.Join(keyWords, " ")
+}
+
+// true if both names are the same game once they are normalized
+func Equal(a, b string) bool {
+	return Key(a) == Key(b)
+}
+
+// the words of the text in lower case without accents. punctuation and symbols are removed
+func words(text string) []string {
+	text = strings.ToLower(removeAccents(text))
+	text = strings.NewReplacer("'", "", "’", "").Replace(text)
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// how similar two names are from 0 to 1 once they are normalized
+// it compares the sets of words, so the order and repeats of words do not matter
+// but words that only one of the names has lower the score. eg. "Doom" and "Doom Eternal" are 0.67
+func Similarity(a, b string) float64 {
+	setA, setB := tokenSet(a), tokenSet(b)
+	if len(setA) == 0 || len(setB) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for token := range setA {
+		if setB[token] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(setA)+len(setB))
+}
+
+func tokenSet(name string) map[string]bool {
+	set := map[string]bool{}
+	for _, token := range Tokens(name) {
+		set[token] = true
+	}
+	return set
+}
+
+// removes accents from letters. eg. "é" -> "e"
+func removeAccents(text string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, text)
+	if err != nil {
+		return text
+	}
+	return result
+}
+
+var romanValues = map[rune]int{'i': 1, 'v': 5, 'x': 10}
+
+// converts a roman numeral from 2 to 39 into a number. eg. "vii" -> "7"
+// "i" and "x" on their own are left as they are, since they are more often a word or a letter
+// eg. "I Am Setsuna", "Mega Man X"
+func romanToNumber(token string) (string, bool) {
+	if token == "i" || token == "x" {
+		return "", false
+	}
+	value, ok := romanValue(token)
+	if !ok || value < 2 {
+		return "", false
+	}
+	return strconv.Itoa(value), true
+}
+
+// the value of a roman numeral from 1 to 39. eg. "vii" -> 7
+// only numerals written the standard way are read. eg. "iiii" and "vx" are not
+func romanValue(token string) (int, bool) {
+	total := 0
+	for i, r := range token {
+		value, ok := romanValues[r]
+		if !ok {
+			return 0, false
+		}
+		// a smaller numeral before a larger one is subtracted. eg. "iv" -> 4
+		if i+1 < len(token) && value < romanValues[rune(token[i+1])] {
+			total -= value
+		} else {
+			total += value
+		}
+	}
+
+	if total < 1 || total > 39 || numberToRoman(total) != token {
+		return 0, false
+	}
+	return total, true
+}
+
+func numberToRoman(n int) string {
+	var sb strings.Builder
+	for _, numeral := range []struct {
+		value  int
+		symbol string
+	}{{10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"}} {
+		for n >= numeral.value {
+			sb.WriteString(numeral.symbol)
+			n -= numeral.value
+		}
+	}
+	return sb.String()
+}
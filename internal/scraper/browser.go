@@ -0,0 +1,184 @@
+package scraper
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// tabs kept open for reuse once their run is done. more tabs are opened when they are all in use
+const maxIdleTabs = 4
+
+// the headless Chrome shared by every search so a new one is not launched for each game
+// it is started on first use and closed by Shutdown
+var browser struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// tabs whose last run succeeded and can be used again
+	idle []*browserTab
+}
+
+// a tab of the shared browser
+type browserTab struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// the robots.txt of the sites the browser goes to is fetched without waiting for a turn at the site
+// since the caller of RunBrowser may already be holding it
+var browserRobotsClient = &http.Client{Transport: &retryTransport{base: agentTransport}}
+
+// goes to link in a tab of the shared browser with the user agent of the app, then runs the actions on the page
+// returns ErrDisallowed without opening the page if the robots.txt of the site does not allow it
+// ctx only limits this run. cancelling it does not close the tab or the browser
+func RunBrowser(ctx context.Context, link string, actions ...chromedp.Action) error {
+	return RunBrowserWithCookies(ctx, link, nil, actions...)
+}
+
+// like RunBrowser, but the cookies are set before the page is opened. eg. to be logged in to the site
+// a cookie without a domain is set for the host of link
+// the tabs share their cookies, so they are deleted again once the run is done
+func RunBrowserWithCookies(ctx context.Context, link string, cookies []*http.Cookie, actions ...chromedp.Action) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := checkRobots(ctx, browserRobotsClient, link); err != nil {
+		return err
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return fmt.Errorf("error parsing link %s: %w", link, err)
+	}
+
+	tab, err := getTab()
+	if err != nil {
+		return err
+	}
+
+	// the tab outlives ctx, so the run is stopped when either is done
+	runCtx, cancel := context.WithCancel(tab.ctx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	before := []chromedp.Action{emulation.SetUserAgentOverride(CurrentUserAgent())}
+	var forget []chromedp.Action
+	for _, cookie := range cookies {
+		domain := cmp.Or(cookie.Domain, u.Hostname())
+		before = append(before, network.SetCookie(cookie.Name, cookie.Value).WithDomain(domain).WithPath(cmp.Or(cookie.Path, "/")))
+		forget = append(forget, network.DeleteCookies(cookie.Name).WithDomain(domain))
+	}
+	actions = append(append(before, chromedp.Navigate(link)), actions...)
+	err = chromedp.Run(runCtx, actions...)
+
+	// the cookies are deleted with the tab before it is given back or closed
+	if len(forget) > 0 {
+		if forgetErr := chromedp.Run(tab.ctx, forget...); forgetErr != nil {
+			log.Println("Error deleting browser cookies:", forgetErr)
+		}
+	}
+
+	// a tab that failed may be left on a broken page, so it is closed instead of reused
+	putTab(tab, err == nil)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// closes every tab and the shared browser
+// the browser is started again if it is needed afterwards
+func Shutdown() {
+	browser.mu.Lock()
+	defer browser.mu.Unlock()
+
+	for _, tab := range browser.idle {
+		tab.cancel()
+	}
+	browser.idle = nil
+
+	if browser.cancel != nil {
+		log.Println("Closing shared browser")
+		browser.cancel()
+	}
+	browser.ctx = nil
+	browser.cancel = nil
+}
+
+// returns an idle tab, or opens a new one if there is none
+func getTab() (*browserTab, error) {
+	browser.mu.Lock()
+	if err := startBrowser(); err != nil {
+		browser.mu.Unlock()
+		return nil, err
+	}
+	if n := len(browser.idle); n > 0 {
+		tab := browser.idle[n-1]
+		browser.idle = browser.idle[:n-1]
+		browser.mu.Unlock()
+		return tab, nil
+	}
+	tabCtx, cancel := chromedp.NewContext(browser.ctx)
+	browser.mu.Unlock()
+
+	// the first run opens the tab. it must use the tab's own context so the tab stays open after
+	if err := chromedp.Run(tabCtx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("error opening browser tab: %w", err)
+	}
+	return &browserTab{ctx: tabCtx, cancel: cancel}, nil
+}
+
+// gives the tab back to be reused, or closes it
+func putTab(tab *browserTab, reuse bool) {
+	browser.mu.Lock()
+	defer browser.mu.Unlock()
+
+	// tabs of a browser that was shut down are already closed
+	if !reuse || tab.ctx.Err() != nil || len(browser.idle) >= maxIdleTabs {
+		tab.cancel()
+		return
+	}
+	browser.idle = append(browser.idle, tab)
+}
+
+// launches the shared browser if it is not running. browser.mu must be held
+func startBrowser() error {
+	if browser.ctx != nil && browser.ctx.Err() == nil {
+		return nil
+	}
+
+	log.Println("Starting shared browser")
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", true),    // Ensure Chrome runs headless
+		chromedp.Flag("disable-gpu", true), // Disable GPU to avoid issues
+		chromedp.Flag("no-sandbox", true),  // Required for some environments
+	)
+
+	// the browser lives until Shutdown, so it is not tied to the context of any search
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
+	browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+	if err := chromedp.Run(browserCtx); err != nil {
+		cancelBrowser()
+		cancelAlloc()
+		return fmt.Errorf("error starting browser: %w", err)
+	}
+
+	browser.ctx = browserCtx
+	browser.cancel = func() {
+		cancelBrowser()
+		cancelAlloc()
+	}
+	browser.idle = nil
+	return nil
+}
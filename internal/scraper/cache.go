@@ -0,0 +1,272 @@
+package scraper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// how long a cached page is used before it is checked with the site again
+const DefaultCacheTTL = 24 * time.Hour
+
+var (
+	cacheMu  sync.RWMutex
+	cacheDir string
+	cacheTTL = DefaultCacheTTL
+	offline  bool
+)
+
+// a page saved to disk along with what is needed to revalidate it
+type cacheEntry struct {
+	URL          string      `json:"url"`
+	StatusCode   int         `json:"statusCode"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	ETag         string      `json:"etag"`
+	LastModified string      `json:"lastModified"`
+	FetchedAt    time.Time   `json:"fetchedAt"`
+}
+
+// sets the directory that pages are cached in and how long they are used for
+// an empty dir turns the cache off
+func SetCache(dir string, ttl time.Duration) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return fmt.Errorf("error creating cache directory: %w", err)
+		}
+	}
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cacheDir = dir
+	cacheTTL = ttl
+	return nil
+}
+
+// when offline, pages are only ever read from the cache and the sites are never contacted
+// a page that is not cached gives ErrOffline
+func SetOffline(value bool) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	offline = value
+}
+
+func isOffline() bool {
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	return offline
+}
+
+// deletes every cached page
+func ClearCache() error {
+	cacheMu.RLock()
+	dir := cacheDir
+	cacheMu.RUnlock()
+	if dir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error reading cache directory: %w", err)
+	}
+	for _, entry := range entries {
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return fmt.Errorf("error deleting cached page: %w", err)
+		}
+	}
+	log.Println("Cleared", len(entries), "cached page(s)")
+	return nil
+}
+
+// file the page for url is cached in. empty if the cache is off
+func cachePath(url string) string {
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	if cacheDir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(cacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// returns the cached page for url, or nil if there is none
+func loadCacheEntry(url string) *cacheEntry {
+	path := cachePath(url)
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url {
+		log.Println("Ignoring unreadable cached page for:", url)
+		return nil
+	}
+	return &entry
+}
+
+// saves the page. a page that cannot be saved is only logged since it can be fetched again
+func saveCacheEntry(entry *cacheEntry) {
+	path := cachePath(entry.URL)
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Println("Error encoding cached page:", err)
+		return
+	}
+
+	// write to a temporary file first so a reader never sees half of a page
+	tmp := fmt.Sprintf("%s.%d.tmp", path, time.Now().UnixNano())
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		log.Println("Error caching page:", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		log.Println("Error caching page:", err)
+		os.Remove(tmp)
+	}
+}
+
+// true if the page was fetched recently enough to be used without asking the site
+func (entry *cacheEntry) fresh() bool {
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	return time.Since(entry.FetchedAt) < cacheTTL
+}
+
+func (entry *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.StatusCode, http.StatusText(entry.StatusCode)),
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
+
+// an empty 404 for req, as a site without the page would send
+func notFoundResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", http.StatusNotFound, http.StatusText(http.StatusNotFound)),
+		StatusCode: http.StatusNotFound,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+}
+
+// answers GET requests from the cache when it can, and caches successful responses
+// a stale page is revalidated with its ETag and Last-Modified so an unchanged page is not downloaded again
+type cacheTransport struct {
+	base http.RoundTripper
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+
+	url := req.URL.String()
+	entry := loadCacheEntry(url)
+	if entry != nil && (entry.fresh() || isOffline()) {
+		log.Println("Using cached page for:", url)
+		return entry.response(req), nil
+	}
+	if isOffline() {
+		// only 200s are cached, so a site without a robots.txt has none cached. the pages cached were allowed
+		if req.URL.Path == "/robots.txt" {
+			return notFoundResponse(req), nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrOffline, url)
+	}
+
+	// ask the site if the cached page is still current
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		log.Println("Cached page is still current for:", url)
+		entry.FetchedAt = time.Now()
+		saveCacheEntry(entry)
+		return entry.response(req), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	saveCacheEntry(&cacheEntry{
+		URL:          url,
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header,
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now(),
+	})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// returns the cached html of a search results page that was loaded in the browser
+// ok is false if it has to be loaded again. offline with nothing cached gives ErrOffline
+func cachedSearchPage(url string) (pageHTML string, ok bool, err error) {
+	entry := loadCacheEntry(url)
+	if entry != nil && (entry.fresh() || isOffline()) {
+		log.Println("Using cached search page for:", url)
+		return string(entry.Body), true, nil
+	}
+	if isOffline() {
+		return "", false, fmt.Errorf("%w: %s", ErrOffline, url)
+	}
+	return "", false, nil
+}
+
+// caches the html of a search results page that was loaded in the browser
+func cacheSearchPage(url string, pageHTML string) {
+	saveCacheEntry(&cacheEntry{
+		URL:        url,
+		StatusCode: http.StatusOK,
+		Body:       []byte(pageHTML),
+		FetchedAt:  time.Now(),
+	})
+}
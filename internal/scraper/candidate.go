@@ -0,0 +1,63 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EZRA-DVLPR/GameList/internal/normalize"
+)
+
+// a candidate whose score is at least this is taken without asking which game was meant
+const ConfidentScore = 0.9
+
+// a game found on a results page that may be the one that was searched for
+type Candidate struct {
+	Title string
+	// year of release. 0 if the results page does not show it
+	Year      int
+	Platforms []string
+	// entire proper link to the page of the game
+	URL string
+	// how similar the title is to what was searched, from 0 to 1
+	Score float64
+}
+
+// text shown to the user when choosing between candidates
+// eg. "Doom (2016) - PC, PS4"
+func (c Candidate) String() string {
+	text := c.Title
+	if c.Year != 0 {
+		text += fmt.Sprintf(" (%d)", c.Year)
+	}
+	if len(c.Platforms) != 0 {
+		text += " - " + strings.Join(c.Platforms, ", ")
+	}
+	return text
+}
+
+// scores each candidate against the query and sorts them from best to worst
+// names are normalized first, so editions, symbols and roman numerals do not lower the score
+// candidates with the same score keep the order of the results page
+func rankCandidates(query string, candidates []Candidate) []Candidate {
+	for i := range candidates {
+		candidates[i].Score = normalize.Similarity(query, candidates[i].Title)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+var yearPattern = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+
+// first year found in the text. 0 if there is none
+func parseYear(text string) int {
+	year, err := strconv.Atoi(yearPattern.FindString(text))
+	if err != nil {
+		return 0
+	}
+	return year
+}
@@ -0,0 +1,131 @@
+package scraper
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// details of a game that can be read from its page. these match the names of the columns in the games table
+const (
+	FieldReleaseDate = "releaseDate"
+	FieldPlatforms   = "platforms"
+	FieldGenres      = "genres"
+	FieldDeveloper   = "developer"
+	FieldPublisher   = "publisher"
+)
+
+// every detail of a game that can be read from its page
+var Fields = []string{FieldReleaseDate, FieldPlatforms, FieldGenres, FieldDeveloper, FieldPublisher}
+
+// returns the detail of the game as text. lists are joined with ", "
+func (game Game) Field(field string) string {
+	switch field {
+	case FieldReleaseDate:
+		return game.ReleaseDate
+	case FieldPlatforms:
+		return strings.Join(game.Platforms, ", ")
+	case FieldGenres:
+		return strings.Join(game.Genres, ", ")
+	case FieldDeveloper:
+		return game.Developer
+	case FieldPublisher:
+		return game.Publisher
+	default:
+		return ""
+	}
+}
+
+// sets the detail of the game from text. lists are split on ","
+// a detail that is already set is kept, so the first of several release dates (eg. NA, EU, JP) is used
+func (game *Game) SetField(field string, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" || game.Field(field) != "" {
+		return
+	}
+	switch field {
+	case FieldReleaseDate:
+		game.ReleaseDate = parseReleaseDate(value)
+	case FieldPlatforms:
+		game.Platforms = splitList(value)
+	case FieldGenres:
+		game.Genres = splitList(value)
+	case FieldDeveloper:
+		game.Developer = value
+	case FieldPublisher:
+		game.Publisher = value
+	}
+}
+
+// returns the detail for the label shown on the site. capitalization and a trailing ":" are ignored
+func (page PageSelectors) field(label string) (string, bool) {
+	label = strings.TrimSuffix(strings.TrimSpace(label), ":")
+	for l, field := range page.Fields {
+		if strings.EqualFold(strings.TrimSuffix(l, ":"), label) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// adds callbacks to c that write the details and cover found on the page to the game
+func (page PageSelectors) readDetails(c *colly.Collector, game *Game) {
+	if page.Info != "" && len(page.Fields) != 0 {
+		c.OnHTML(page.Info, func(e *colly.HTMLElement) {
+			label := e.ChildText(page.InfoLabel)
+			field, ok := page.field(label)
+			if !ok {
+				return
+			}
+			// the value is the rest of the element after its label
+			value := strings.TrimPrefix(strings.TrimSpace(e.Text), label)
+			game.SetField(field, strings.TrimPrefix(strings.TrimSpace(value), ":"))
+		})
+	}
+
+	if page.Cover != "" {
+		c.OnHTML(page.Cover, func(e *colly.HTMLElement) {
+			if game.CoverURL == "" && e.Attr("src") != "" {
+				game.CoverURL = e.Request.AbsoluteURL(e.Attr("src"))
+			}
+		})
+	}
+}
+
+// splits a list shown on a site. eg. "PC, Nintendo Switch" gives ["PC", "Nintendo Switch"]
+func splitList(value string) (items []string) {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return
+}
+
+// "1st", "2nd", "23rd", "25th" -> "1", "2", "23", "25"
+var ordinalPattern = regexp.MustCompile(`(\d+)(st|nd|rd|th)\b`)
+
+// ways the sites show a release date, and how much of the date is known from each
+var releaseDateLayouts = []struct{ layout, format string }{
+	{"January 2, 2006", "2006-01-02"},
+	{"Jan 2, 2006", "2006-01-02"},
+	{"2 January 2006", "2006-01-02"},
+	{"1/2/2006", "2006-01-02"},
+	{"2006-01-02", "2006-01-02"},
+	{"January 2006", "2006-01"},
+	{"2006", "2006"},
+}
+
+// the release date as "2006-01-02" so it sorts correctly. a date that cannot be read is kept as it is
+// eg. "January 25th, 2018" and "01/25/2018" both give "2018-01-25". only a year gives "2018"
+func parseReleaseDate(value string) string {
+	text := ordinalPattern.ReplaceAllString(strings.TrimSpace(value), "$1")
+	for _, l := range releaseDateLayouts {
+		if date, err := time.Parse(l.layout, text); err == nil {
+			return date.Format(l.format)
+		}
+	}
+	return value
+}
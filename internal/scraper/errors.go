@@ -0,0 +1,29 @@
+package scraper
+
+import "errors"
+
+// errors returned by the searching and fetching functions
+// callers should compare with errors.Is since they are usually wrapped with the game name or link
+var (
+	// the search source returned no link for the given game
+	ErrNoResults = errors.New("no search results found")
+
+	// the page for the game was reached, but no completion times were on it
+	ErrNoTimeData = errors.New("no time data found")
+
+	// the page of the game loaded, but its name and times were not where the selectors look for them
+	// the site has most likely changed its layout, so the selectors need to be updated
+	ErrLayoutChanged = errors.New("page layout changed")
+
+	// the site could not be reached or returned an error
+	ErrSourceUnavailable = errors.New("source unavailable")
+
+	// the robots.txt of the site does not allow the page to be fetched
+	ErrDisallowed = errors.New("page disallowed by robots.txt")
+
+	// offline mode is on and the page is not in the cache
+	ErrOffline = errors.New("page not cached while offline")
+
+	// the selectors file is not valid yaml or is missing a required selector
+	ErrInvalidSelectors = errors.New("invalid selectors")
+)
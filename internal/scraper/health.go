@@ -0,0 +1,113 @@
+package scraper
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/gocolly/colly"
+)
+
+// which of the selectors of a game page matched, so a site whose layout changed
+// can be told apart from a game no one has submitted times for
+type pageHealth struct {
+	mu                   sync.Mutex
+	name, times, labeled bool
+}
+
+// adds callbacks to c that record which of the selectors of the page matched
+func (page PageSelectors) watchLayout(c *colly.Collector) *pageHealth {
+	health := &pageHealth{}
+	c.OnHTML(page.Name, func(e *colly.HTMLElement) {
+		health.mu.Lock()
+		defer health.mu.Unlock()
+		health.name = true
+	})
+	c.OnHTML(page.Times, func(e *colly.HTMLElement) {
+		// a game with no times still has its labels. eg. "Main Story" with "--"
+		labeled := false
+		e.ForEach(page.Item, func(_ int, el *colly.HTMLElement) {
+			if _, ok := page.category(el.ChildText(page.Label)); ok {
+				labeled = true
+			}
+		})
+
+		health.mu.Lock()
+		defer health.mu.Unlock()
+		health.times = true
+		health.labeled = health.labeled || labeled
+	})
+	return health
+}
+
+// returns ErrLayoutChanged if the page loaded but the name, the times, or any known label of a time were not on it
+// the site is marked as broken until one of its pages matches again
+func (health *pageHealth) check(site string, link string) error {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	var missing []string
+	if !health.name {
+		missing = append(missing, "name")
+	}
+	if !health.times {
+		missing = append(missing, "times")
+	} else if !health.labeled {
+		missing = append(missing, "labels of the times")
+	}
+
+	if len(missing) == 0 {
+		setBroken(site, nil)
+		return nil
+	}
+	err := fmt.Errorf("%w: %s page has no %s: %s", ErrLayoutChanged, site, strings.Join(missing, ", "), link)
+	log.Println(err)
+	setBroken(site, err)
+	return err
+}
+
+var (
+	brokenMu        sync.Mutex
+	broken          = map[string]error{}
+	brokenListeners []func(map[string]error)
+)
+
+// records whether the layout of the site changed. a nil err means its pages match the selectors
+// the listeners are told every time a page of a site does not match, and once when the site works again
+func setBroken(site string, err error) {
+	brokenMu.Lock()
+	_, wasBroken := broken[site]
+	if !wasBroken && err == nil {
+		brokenMu.Unlock()
+		return
+	}
+	if err != nil {
+		broken[site] = err
+	} else {
+		delete(broken, site)
+	}
+	current := maps.Clone(broken)
+	listeners := brokenListeners
+	brokenMu.Unlock()
+
+	for _, listener := range listeners {
+		listener(current)
+	}
+}
+
+// returns the error of each site whose pages no longer match the selectors, by site
+func BrokenSources() map[string]error {
+	brokenMu.Lock()
+	defer brokenMu.Unlock()
+	return maps.Clone(broken)
+}
+
+// calls listener with every broken site whenever a site breaks or is working again
+// it may be called from any goroutine
+func AddBrokenSourcesListener(listener func(map[string]error)) {
+	brokenMu.Lock()
+	defer brokenMu.Unlock()
+	brokenListeners = append(brokenListeners, listener)
+}
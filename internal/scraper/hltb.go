@@ -0,0 +1,232 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// the search API is turned off in the selectors file
+var errHLTBAPIOff = errors.New("HLTB search API is not set in the selectors")
+
+// talks to the JSON search endpoint that the HLTB site itself uses
+// this is much faster than loading the results page in the browser
+type HLTBClient struct {
+	// eg. "https://howlongtobeat.com"
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// requests of the HLTB searches wait for their turn at the site like every other request
+var hltbHTTPClient = &http.Client{Transport: &retryTransport{base: &limitTransport{base: agentTransport}}}
+
+// client for the HLTB site that is currently set in the base urls
+func newHLTBClient() *HLTBClient {
+	return &HLTBClient{BaseURL: CurrentBaseURLs().HLTB, HTTPClient: hltbHTTPClient}
+}
+
+// a game found by the HLTB search API
+type HLTBResult struct {
+	ID   int
+	Name string
+	// times in hours. -1 when no one has submitted a time for the category
+	Main, MainPlus, Comp float32
+	// number of submissions behind each category of time
+	Polled map[string]int
+	// 0 if HLTB does not know the year of release
+	Year      int
+	Platforms []string
+	// entire proper link to the cover image. empty if the game has none
+	Image string
+}
+
+// entire proper link to the page of the game on HLTB
+func (c *HLTBClient) GameURL(id int) string {
+	return c.BaseURL + "/game/" + strconv.Itoa(id)
+}
+
+// given a name for a game, returns the games found by the HLTB search API in the order HLTB gives them
+// no games found gives ErrNoResults
+func (c *HLTBClient) Search(ctx context.Context, query string) ([]HLTBResult, error) {
+	path := CurrentSelectors().HLTB.Search.API
+	if path == "" {
+		return nil, errHLTBAPIOff
+	}
+	endpoint := c.BaseURL + path
+
+	// the API is asked with a POST, so the response is cached by the query instead of by the url
+	cacheKey := endpoint + "?q=" + url.QueryEscape(query)
+	body, cached, err := cachedSearchPage(cacheKey)
+	if err != nil {
+		return nil, err
+	}
+	if !cached {
+		body, err = c.post(ctx, endpoint, query)
+		if err != nil {
+			return nil, err
+		}
+		cacheSearchPage(cacheKey, body)
+	}
+
+	var resp hltbSearchResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return nil, fmt.Errorf("%w: error decoding HLTB search response: %v", ErrSourceUnavailable, err)
+	}
+	if len(resp.Data) == 0 {
+		return nil, ErrNoResults
+	}
+
+	results := make([]HLTBResult, 0, len(resp.Data))
+	for _, g := range resp.Data {
+		results = append(results, g.result(c.BaseURL))
+	}
+	return results, nil
+}
+
+// makes the search request and returns the body of the response
+func (c *HLTBClient) post(ctx context.Context, endpoint string, query string) (string, error) {
+	reqBody, err := json.Marshal(newHLTBSearchRequest(query))
+	if err != nil {
+		return "", fmt.Errorf("error encoding HLTB search request: %w", err)
+	}
+
+	if err := checkRobots(ctx, c.HTTPClient, endpoint); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("error creating HLTB search request: %w", err)
+	}
+	// the API refuses requests that do not look like they come from the site
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Origin", c.BaseURL)
+	req.Header.Set("Referer", c.BaseURL+"/")
+	req.Header.Set("User-Agent", CurrentUserAgent())
+
+	log.Println("Searching HLTB API for game:", query)
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("%w: %v", ErrSourceUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: HLTB search API returned %s", ErrSourceUnavailable, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrSourceUnavailable, err)
+	}
+	return string(data), nil
+}
+
+// body of a search request as the HLTB site sends it
+type hltbSearchRequest struct {
+	SearchType    string         `json:"searchType"`
+	SearchTerms   []string       `json:"searchTerms"`
+	SearchPage    int            `json:"searchPage"`
+	Size          int            `json:"size"`
+	SearchOptions map[string]any `json:"searchOptions"`
+	UseCache      bool           `json:"useCache"`
+}
+
+func newHLTBSearchRequest(query string) hltbSearchRequest {
+	return hltbSearchRequest{
+		SearchType:  "games",
+		SearchTerms: strings.Fields(query),
+		SearchPage:  1,
+		Size:        20,
+		SearchOptions: map[string]any{
+			"games": map[string]any{
+				"userId":        0,
+				"platform":      "",
+				"sortCategory":  "popular",
+				"rangeCategory": "main",
+				"rangeTime":     map[string]any{"min": nil, "max": nil},
+				"gameplay":      map[string]any{"perspective": "", "flow": "", "genre": ""},
+				"rangeYear":     map[string]any{"min": "", "max": ""},
+				"modifier":      "",
+			},
+			"users":      map[string]any{"sortCategory": "postcount"},
+			"filter":     "",
+			"sort":       0,
+			"randomizer": 0,
+		},
+		UseCache: true,
+	}
+}
+
+type hltbSearchResponse struct {
+	Data []hltbSearchGame `json:"data"`
+}
+
+// a game in the search response. times are in seconds
+type hltbSearchGame struct {
+	ID        int    `json:"game_id"`
+	Name      string `json:"game_name"`
+	Image     string `json:"game_image"`
+	Main      int    `json:"comp_main"`
+	MainPlus  int    `json:"comp_plus"`
+	Comp      int    `json:"comp_100"`
+	MainCount int    `json:"comp_main_count"`
+	PlusCount int    `json:"comp_plus_count"`
+	CompCount int    `json:"comp_100_count"`
+	Platforms string `json:"profile_platform"`
+	Year      int    `json:"release_world"`
+}
+
+func (g hltbSearchGame) result(baseURL string) HLTBResult {
+	result := HLTBResult{
+		ID:       g.ID,
+		Name:     strings.TrimSpace(g.Name),
+		Main:     hltbHours(g.Main),
+		MainPlus: hltbHours(g.MainPlus),
+		Comp:     hltbHours(g.Comp),
+		Polled: map[string]int{
+			CategoryMain:     g.MainCount,
+			CategoryMainPlus: g.PlusCount,
+			CategoryComp:     g.CompCount,
+		},
+		Year: g.Year,
+	}
+	for _, platform := range strings.Split(g.Platforms, ",") {
+		if platform = strings.TrimSpace(platform); platform != "" {
+			result.Platforms = append(result.Platforms, platform)
+		}
+	}
+	if g.Image != "" {
+		result.Image = baseURL + "/games/" + g.Image
+	}
+	return result
+}
+
+// seconds to hours rounded to the half hour like the site shows them. no time gives -1
+func hltbHours(seconds int) float32 {
+	if seconds <= 0 {
+		return -1
+	}
+	return float32(math.Round(float64(seconds)/1800) / 2)
+}
+
+// the game as a candidate for a search, linking to its page on HLTB
+func (r HLTBResult) candidate(c *HLTBClient) Candidate {
+	return Candidate{
+		Title:     r.Name,
+		Year:      r.Year,
+		Platforms: r.Platforms,
+		URL:       c.GameURL(r.ID),
+	}
+}
@@ -0,0 +1,96 @@
+package scraper
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// limits how many requests are made to a site at the same time and how often they start
+// so that a batch of many games does not flood any one site
+type hostLimiter struct {
+	slots chan struct{}
+	delay time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// limits used until SetHostLimit is called
+const (
+	// requests allowed to each site at the same time
+	DefaultHostParallelism = 2
+	// minimum time between the start of two requests to the same site
+	DefaultHostDelay = 500 * time.Millisecond
+)
+
+var (
+	limitersMu sync.Mutex
+	limiters   = map[string]*hostLimiter{}
+
+	hostParallelism = DefaultHostParallelism
+	hostDelay       = DefaultHostDelay
+)
+
+// sets the limits used for every site. values below the minimum are raised to it
+// requests that are already waiting keep the limits they started with
+func SetHostLimit(parallelism int, delay time.Duration) {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	hostParallelism = max(parallelism, 1)
+	hostDelay = max(delay, 0)
+	limiters = map[string]*hostLimiter{}
+}
+
+// returns the limiter shared by every request to the host
+// "www.example.com" and "example.com" are the same site
+func limiterFor(host string) *hostLimiter {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	l, ok := limiters[host]
+	if !ok {
+		l = &hostLimiter{
+			slots: make(chan struct{}, hostParallelism),
+			delay: hostDelay,
+		}
+		limiters[host] = l
+	}
+	return l
+}
+
+// waits until a request to the host is allowed or ctx is done
+// the returned func must be called once the request is finished
+func waitForHost(ctx context.Context, host string) (release func(), err error) {
+	l := limiterFor(host)
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release = func() { <-l.slots }
+
+	// reserve the next start time so that waiting requests are spaced out
+	l.mu.Lock()
+	start := time.Now()
+	if l.next.After(start) {
+		start = l.next
+	}
+	l.next = start.Add(l.delay)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(start))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return release, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
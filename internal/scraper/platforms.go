@@ -0,0 +1,65 @@
+package scraper
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly"
+)
+
+// categories of time the sites break down by platform
+var PlatformCategories = []string{CategoryMain, CategoryMainPlus, CategoryComp}
+
+// returns the time on the platform for the category. -1 if the category is unknown
+func (pt PlatformTime) Time(category string) float32 {
+	switch category {
+	case CategoryMain:
+		return pt.Main
+	case CategoryMainPlus:
+		return pt.MainPlus
+	case CategoryComp:
+		return pt.Comp
+	default:
+		return -1
+	}
+}
+
+// sets the time on the platform for the category. unknown categories are ignored
+func (pt *PlatformTime) SetTime(category string, value float32) {
+	switch category {
+	case CategoryMain:
+		pt.Main = value
+	case CategoryMainPlus:
+		pt.MainPlus = value
+	case CategoryComp:
+		pt.Comp = value
+	}
+}
+
+// adds a callback to c that writes the times on each platform to the game
+// rows with no platform name are skipped
+func (page PageSelectors) readPlatformTimes(c *colly.Collector, game *Game) {
+	platforms := page.Platforms
+	if platforms == nil {
+		return
+	}
+
+	c.OnHTML(platforms.Table, func(e *colly.HTMLElement) {
+		e.DOM.Find(platforms.Row).Each(func(_ int, row *goquery.Selection) {
+			cells := row.Find(platforms.Cell)
+			name := strings.TrimSpace(cells.Eq(0).Text())
+			if name == "" {
+				return
+			}
+
+			pt := PlatformTime{Platform: name, Main: -1, MainPlus: -1, Comp: -1}
+			if platforms.Polled > 0 {
+				pt.Polled = parseCount(cells.Eq(platforms.Polled).Text())
+			}
+			for category, i := range platforms.Times {
+				pt.SetTime(category, statTime(cells, i))
+			}
+			game.PlatformTimes = append(game.PlatformTimes, pt)
+		})
+	})
+}
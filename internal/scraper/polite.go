@@ -0,0 +1,203 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+// sent with every request so the sites can tell who is asking for their pages
+// it is also the agent looked for in the robots.txt of each site
+const DefaultUserAgent = "GameList/1.0 (+https://github.com/EZRA-DVLPR/GameList)"
+
+// retries used until SetRetry is called
+const (
+	// times a request is tried again after the site says it is busy or broken
+	DefaultRetries = 3
+	// time waited before the first retry. each retry after that waits twice as long
+	DefaultBackoff = 2 * time.Second
+	// longest time waited before a retry, even if the site asks for longer
+	maxBackoff = time.Minute
+)
+
+var (
+	politeMu  sync.RWMutex
+	userAgent = DefaultUserAgent
+	retries   = DefaultRetries
+	backoff   = DefaultBackoff
+
+	// the rules of each site's robots.txt, by host. nil when the site has none
+	robotsMu sync.Mutex
+	robots   = map[string]*robotstxt.RobotsData{}
+)
+
+// sets the user agent sent to every site. empty uses DefaultUserAgent
+func SetUserAgent(agent string) {
+	politeMu.Lock()
+	defer politeMu.Unlock()
+
+	userAgent = strings.TrimSpace(agent)
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+}
+
+// the user agent sent to every site
+func CurrentUserAgent() string {
+	politeMu.RLock()
+	defer politeMu.RUnlock()
+	return userAgent
+}
+
+// sets how many times a busy or broken site is asked again, and how long is waited before the first retry
+func SetRetry(n int, wait time.Duration) {
+	politeMu.Lock()
+	defer politeMu.Unlock()
+
+	retries = max(n, 0)
+	backoff = max(wait, 0)
+}
+
+func retrySettings() (int, time.Duration) {
+	politeMu.RLock()
+	defer politeMu.RUnlock()
+	return retries, backoff
+}
+
+// true if the response says the site is busy (429) or broken (5xx), so asking again later may work
+func shouldRetry(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// asks the site again when it is busy or broken, waiting longer each time
+// a Retry-After header from the site is used instead of the wait when it is longer
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	n, wait := retrySettings()
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || !shouldRetry(resp) || attempt >= n {
+			return resp, err
+		}
+
+		// the body of a request can only be read once, so a new one is needed to send it again
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		delay := min(retryAfter(resp, wait<<attempt), maxBackoff)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		log.Printf("%s returned %s. Trying again in %s\n", req.URL.Host, resp.Status, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// sends the user agent of the app with every request, including those colly makes for robots.txt
+var agentTransport http.RoundTripper = agentRoundTripper{base: http.DefaultTransport}
+
+type agentRoundTripper struct {
+	base http.RoundTripper
+}
+
+func (t agentRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", CurrentUserAgent())
+	return t.base.RoundTrip(req)
+}
+
+// the wait asked for by the Retry-After header of the response if it is longer than wait
+// the header is either a number of seconds or a date
+func retryAfter(resp *http.Response, wait time.Duration) time.Duration {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return wait
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(wait, time.Duration(seconds)*time.Second)
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(wait, time.Until(date))
+	}
+	return wait
+}
+
+// returns ErrDisallowed if the robots.txt of the site does not let the user agent fetch the link
+// colly checks this on its own, so this is only needed for requests made without it
+// the robots.txt of each site is only fetched once. a site with none allows everything
+func checkRobots(ctx context.Context, client *http.Client, link string) error {
+	u, err := url.Parse(link)
+	if err != nil {
+		return fmt.Errorf("error parsing link %s: %w", link, err)
+	}
+
+	robotsMu.Lock()
+	rules, ok := robots[u.Host]
+	robotsMu.Unlock()
+
+	if !ok {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.Scheme+"://"+u.Host+"/robots.txt", nil)
+		if err != nil {
+			return fmt.Errorf("error creating robots.txt request: %w", err)
+		}
+		req.Header.Set("User-Agent", CurrentUserAgent())
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%w: %v", ErrSourceUnavailable, err)
+		}
+		rules, err = robotstxt.FromResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			// a robots.txt that cannot be read is treated as if the site had none
+			log.Println("Error reading robots.txt of", u.Host, ":", err)
+			rules = nil
+		}
+
+		robotsMu.Lock()
+		robots[u.Host] = rules
+		robotsMu.Unlock()
+	}
+
+	if rules != nil && !rules.TestAgent(u.EscapedPath(), CurrentUserAgent()) {
+		log.Println("robots.txt of", u.Host, "does not allow:", u.EscapedPath())
+		return fmt.Errorf("%w: %s", ErrDisallowed, link)
+	}
+	return nil
+}
+
+// forgets the robots.txt of every site so they are fetched again when the sites change
+func resetRobots() {
+	robotsMu.Lock()
+	defer robotsMu.Unlock()
+	robots = map[string]*robotstxt.RobotsData{}
+}
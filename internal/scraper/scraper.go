@@ -1,10 +1,14 @@
 package scraper
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
-	"strconv"
+	"net/http"
 	"strings"
 
+	"github.com/EZRA-DVLPR/GameList/internal/normalize"
 	"github.com/gocolly/colly"
 )
 
@@ -12,42 +16,121 @@ type Game struct {
 	Name, HLTBUrl, CompletionatorUrl string
 	Favorite                         int
 	Main, MainPlus, Comp             float32
+
+	// times for other ways of playing. -1 when the site has none, which is most games
+	Coop, Vs, Speedrun float32
+
+	// number of submissions behind each category of time, for sources that report it
+	Polled map[string]int
+
+	// how far apart the submitted times of each category are, for sources that report it
+	Ranges map[string]TimeRange
+
+	// times on each platform the site breaks them down by, in the order the site shows them
+	PlatformTimes []PlatformTime
+
+	// each category of time as the site showed it, so odd values can be checked. eg. "12½ Hours"
+	Raw map[string]string
+
+	// details of the game. empty when the site does not show them
+	// the release date is "2006-01-02" when it could be read, o/w as the site shows it
+	ReleaseDate          string
+	Platforms, Genres    []string
+	Developer, Publisher string
+	CoverURL             string
+}
+
+// the spread of the times submitted for a category, in hours. -1 when the source does not report it
+type TimeRange struct {
+	Low, Median, High float32
+}
+
+// the times of a game on one platform. -1 for a category with no time
+type PlatformTime struct {
+	Platform             string
+	Polled               int
+	Main, MainPlus, Comp float32
+}
+
+// categories of completion times. these match the names of the columns in the games table
+const (
+	CategoryMain     = "main"
+	CategoryMainPlus = "mainPlus"
+	CategoryComp     = "comp"
+	CategoryCoop     = "coop"
+	CategoryVs       = "vs"
+	CategorySpeedrun = "speedrun"
+)
+
+// every category of completion time in the order they are displayed
+var Categories = []string{CategoryMain, CategoryMainPlus, CategoryComp, CategoryCoop, CategoryVs, CategorySpeedrun}
+
+// returns the time of the game for the category. -1 if the category is unknown
+func (game Game) Time(category string) float32 {
+	switch category {
+	case CategoryMain:
+		return game.Main
+	case CategoryMainPlus:
+		return game.MainPlus
+	case CategoryComp:
+		return game.Comp
+	case CategoryCoop:
+		return game.Coop
+	case CategoryVs:
+		return game.Vs
+	case CategorySpeedrun:
+		return game.Speedrun
+	default:
+		return -1
+	}
+}
+
+// sets the time of the game for the category. unknown categories are ignored
+func (game *Game) SetTime(category string, value float32) {
+	switch category {
+	case CategoryMain:
+		game.Main = value
+	case CategoryMainPlus:
+		game.MainPlus = value
+	case CategoryComp:
+		game.Comp = value
+	case CategoryCoop:
+		game.Coop = value
+	case CategoryVs:
+		game.Vs = value
+	case CategorySpeedrun:
+		game.Speedrun = value
+	}
 }
 
 // given the name of a game as a string, search HLTB, get its data and return as game struct
-// if the search fails, then searches bing and gets the first hit
-func SearchGameHLTB(gameName string) Game {
-	log.Println("Searching HLTB for game...")
-
-	searchRes := searchHLTB(gameName)
-	if searchRes == "" {
-		log.Println("Querying HLTB Failed. Retrying through Bing Search...")
-		searchRes = searchBing(gameName)
-
-		// if still no link results, then return empty game
-		// all empty strings and numerical values as -1
-		if searchRes == "" {
-			log.Println("No Link found. Process Aborted!")
-			var emptyGame Game
-			emptyGame.Main = -1
-			emptyGame.MainPlus = -1
-			emptyGame.Comp = -1
-			return emptyGame
-		}
+// the game that best matches the name is used
+// if the search fails, then the enabled web searches are searched for the game on HLTB
+func SearchGameHLTB(ctx context.Context, gameName string) (Game, error) {
+	return SearchGame(ctx, hltbSource{}, gameName)
+}
 
-		log.Println("Link obtained. Web Scraping process beginning...")
-		return FetchHLTB(searchRes)
+// given the name of a game as a string, search HLTB and return the games found, best match first
+// only HLTB itself is searched. SearchCandidates also tries the enabled web searches
+func SearchCandidatesHLTB(ctx context.Context, gameName string) ([]Candidate, error) {
+	// names from stores have editions and symbols that the search does not find
+	query := normalize.Query(gameName)
+	log.Println("Searching HLTB for game:", query)
 
-	} else {
-		log.Println("Link obtained. Web Scraping process beginning...")
-		return FetchHLTB("https://howlongtobeat.com" + searchRes)
+	candidates, err := searchHLTB(ctx, query)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("searching HLTB for %q: %w", gameName, err)
 	}
+	return candidates, nil
 }
 
 // given the entire proper link for HLTB, obtain information for the game
-func FetchHLTB(link string) (game Game) {
+func FetchHLTB(ctx context.Context, link string) (game Game, err error) {
 	// declare the collector object so the scraping process can begin
-	c := colly.NewCollector()
+	c := newCollector(ctx)
 
 	// establish connection to HLTB
 	c.OnRequest(func(r *colly.Request) {
@@ -55,64 +138,35 @@ func FetchHLTB(link string) (game Game) {
 	})
 
 	// log that there was a problem accessing the URL
-	c.OnError(func(_ *colly.Response, err error) {
-		log.Println("Something went wrong:", err)
+	c.OnError(func(_ *colly.Response, visitErr error) {
+		log.Println("Something went wrong:", visitErr)
+		err = fmt.Errorf("%w: %w", ErrSourceUnavailable, visitErr)
 	})
 
 	// update the Main Story, Main + Sides, and Completionist fields of the game struct
-	c.OnHTML("div.GameStats_game_times__KHrRY", func(e *colly.HTMLElement) {
-		e.ForEach("li", func(_ int, el *colly.HTMLElement) {
-			// dont grab the data that is from the following categories:
-			// 		"All Styles"
-			// 		"Vs."
-			if (el.ChildText("h4") != "All Styles") && (el.ChildText("h4") != "Vs.") {
-				// if the current label is "Co-Op" or "Single-Player"
-				// check if there is a value for "Main Story"
-				// 			if true: compare the values and take the higher
-				// 			else: make "Main Story" data
-				// else write the the data as is
-
-				if (el.ChildText("h4") == "Co-Op") || (el.ChildText("h4") == "Single-Player") {
-					// if main story data exists, overwrite only if new data is greater
-					if (game.Main != 0) && (game.Main < cleanTime(el.ChildText("h5"))) {
-						// There's no Main Story data so write it
-						game.Main = cleanTime(el.ChildText("h5"))
-					} else if game.Main == 0 {
-						game.Main = cleanTime(el.ChildText("h5"))
-					}
-				}
-
-				// write the data for Main Story, Main + Sides, and Completionist
-				if el.ChildText("h4") == "Main Story" {
-					game.Main = cleanTime(el.ChildText("h5"))
-				}
-				if el.ChildText("h4") == "Main + Sides" {
-					game.MainPlus = cleanTime(el.ChildText("h5"))
-				}
-				if el.ChildText("h4") == "Completionist" {
-					game.Comp = cleanTime(el.ChildText("h5"))
-				}
-			}
-		})
-		// when finished obtaining all the data, fill all empty values with "-1"
-
-		if game.Main == 0 {
-			game.Main = -1
-		}
-		if game.MainPlus == 0 {
-			game.MainPlus = -1
-		}
-		if game.Comp == 0 {
-			game.Comp = -1
-		}
+	page := CurrentSelectors().HLTB.Game
+	c.OnHTML(page.Times, func(e *colly.HTMLElement) {
+		page.readTimes(e, &game)
 	})
 
 	// set the game name
-	c.OnHTML("div.GameHeader_profile_header__q_PID", func(e *colly.HTMLElement) {
+	c.OnHTML(page.Name, func(e *colly.HTMLElement) {
 		// remove the stuff after the <br>
 		game.Name = strings.TrimSpace(e.Text)
 	})
 
+	// set the release date, platforms, genres, developer, publisher and cover
+	page.readDetails(c, &game)
+
+	// set the number of submissions and spread of each time
+	page.readStats(c, &game)
+
+	// set the times on each platform
+	page.readPlatformTimes(c, &game)
+
+	// notice when the page no longer looks like the selectors expect
+	health := page.watchLayout(c)
+
 	// when the data is acquired, log it and attach URL
 	c.OnScraped(func(r *colly.Response) {
 		// attach the url to the game
@@ -124,33 +178,49 @@ func FetchHLTB(link string) (game Game) {
 		log.Println("Data Obtained for game from HLTB link:", r.Request.URL)
 	})
 
-	c.Visit(link)
+	if visitErr := c.Visit(link); visitErr != nil && err == nil {
+		err = visitError(link, visitErr)
+	}
+	if ctx.Err() != nil {
+		return emptyGame(), ctx.Err()
+	}
+	if err != nil {
+		return emptyGame(), err
+	}
+	if err := health.check("HLTB", link); err != nil {
+		return emptyGame(), err
+	}
 
-	return
+	return game, checkTimeData(&game, link)
 }
 
 // given the name of a game as a string, search Completionator, get its data and return as game struct
-func SearchGameCompletionator(gameName string) Game {
-	log.Println("Searching Completionator for game...")
-	searchRes := searchCompletionator(gameName)
-	if searchRes == "" {
-		log.Println("No Link found. Process Aborted!")
-		var emptyGame Game
-		// if still no link results, then return empty game
-		// all empty strings and numerical values as -1
-		emptyGame.Main = -1
-		emptyGame.MainPlus = -1
-		emptyGame.Comp = -1
-		return emptyGame
-	} else {
-		log.Println("Link obtained. Web Scraping process beginning...")
-		return FetchCompletionator("https://completionator.com" + searchRes)
-	}
-}
-
-func FetchCompletionator(link string) (game Game) {
+// the game that best matches the name is used
+// if the search fails, then the enabled web searches are searched for the game on Completionator
+func SearchGameCompletionator(ctx context.Context, gameName string) (Game, error) {
+	return SearchGame(ctx, completionatorSource{}, gameName)
+}
+
+// given the name of a game as a string, search Completionator and return the games found, best match first
+// only Completionator itself is searched. SearchCandidates also tries the enabled web searches
+func SearchCandidatesCompletionator(ctx context.Context, gameName string) ([]Candidate, error) {
+	query := normalize.Query(gameName)
+	log.Println("Searching Completionator for game:", query)
+
+	candidates, err := searchCompletionator(ctx, query)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("searching Completionator for %q: %w", gameName, err)
+	}
+	return candidates, nil
+}
+
+// given the entire proper link for Completionator, obtain information for the game
+func FetchCompletionator(ctx context.Context, link string) (game Game, err error) {
 	// declare the collector object so the scraping process can begin
-	c := colly.NewCollector()
+	c := newCollector(ctx)
 
 	// establish connection to Completionator
 	c.OnRequest(func(r *colly.Request) {
@@ -158,48 +228,36 @@ func FetchCompletionator(link string) (game Game) {
 	})
 
 	// log that there was a problem accessing the URL
-	c.OnError(func(_ *colly.Response, err error) {
-		log.Println("Something went wrong:", err)
+	c.OnError(func(_ *colly.Response, visitErr error) {
+		log.Println("Something went wrong:", visitErr)
+		err = fmt.Errorf("%w: %w", ErrSourceUnavailable, visitErr)
 	})
 
 	// update the Main Story, Main + Sides, and Completionist fields of the game struct
-	c.OnHTML("div.row", func(e *colly.HTMLElement) {
-		e.ForEach("div.col-6", func(_ int, el *colly.HTMLElement) {
-			// dont grab the data that is from the following categories:
-			// 		"speed run"
-			if el.ChildText("h5") != "speed run" {
-				// write the data for Main Story, Main + Sides, and Completionist
-				// In completionator they are saved as "core + few", "core + lots", "completionated"
-				if el.ChildText("h5") == "core + few" {
-					game.Main = cleanTime(el.ChildText("h3"))
-				}
-				if el.ChildText("h5") == "core + lots" {
-					game.MainPlus = cleanTime(el.ChildText("h3"))
-				}
-				if el.ChildText("h5") == "completionated" {
-					game.Comp = cleanTime(el.ChildText("h3"))
-				}
-			}
-		})
-		// when finished obtaining all the data, fill all empty values with "-1"
-
-		if game.Main == 0 {
-			game.Main = -1
-		}
-		if game.MainPlus == 0 {
-			game.MainPlus = -1
-		}
-		if game.Comp == 0 {
-			game.Comp = -1
-		}
+	// In completionator they are saved as "core + few", "core + lots", "completionated"
+	page := CurrentSelectors().Completionator.Game
+	c.OnHTML(page.Times, func(e *colly.HTMLElement) {
+		page.readTimes(e, &game)
 	})
 
 	// set the game name
-	c.OnHTML("h2.game-details-title", func(e *colly.HTMLElement) {
+	c.OnHTML(page.Name, func(e *colly.HTMLElement) {
 		// grab the first child of h2 tag
 		game.Name = strings.TrimSpace(e.DOM.Contents().First().Text())
 	})
 
+	// set the release date, platforms, genres, developer, publisher and cover
+	page.readDetails(c, &game)
+
+	// set the number of submissions and spread of each time
+	page.readStats(c, &game)
+
+	// set the times on each platform
+	page.readPlatformTimes(c, &game)
+
+	// notice when the page no longer looks like the selectors expect
+	health := page.watchLayout(c)
+
 	// when the data is acquired, log it and attach URL
 	c.OnScraped(func(r *colly.Response) {
 		// attach the url to the game
@@ -211,33 +269,94 @@ func FetchCompletionator(link string) (game Game) {
 		log.Println("Data Obtained for game from Completionator link:", r.Request.URL)
 	})
 
-	c.Visit(link)
+	if visitErr := c.Visit(link); visitErr != nil && err == nil {
+		err = visitError(link, visitErr)
+	}
+	if ctx.Err() != nil {
+		return emptyGame(), ctx.Err()
+	}
+	if err != nil {
+		return emptyGame(), err
+	}
+	if err := health.check("Completionator", link); err != nil {
+		return emptyGame(), err
+	}
 
-	return
+	return game, checkTimeData(&game, link)
 }
 
-func cleanTime(time string) (cleanTime float32) {
-	// if no time recorded, then return -1
-	if time == "--" {
-		return -1
+// collector whose requests are cancelled along with ctx, answered from the cache when possible,
+// and otherwise wait for their turn at the site and are tried again if the site is busy
+// pages the robots.txt of the site does not allow are not visited
+// colly has no context support of its own, so the context is attached to every request by the transport
+func newCollector(ctx context.Context) *colly.Collector {
+	c := colly.NewCollector()
+	c.UserAgent = CurrentUserAgent()
+	c.IgnoreRobotsTxt = false
+	c.WithTransport(&contextTransport{
+		ctx:  ctx,
+		base: &cacheTransport{base: &retryTransport{base: &limitTransport{base: agentTransport}}},
+	})
+	return c
+}
+
+// the error for a page colly could not visit. a page robots.txt does not allow gives ErrDisallowed
+func visitError(link string, visitErr error) error {
+	if errors.Is(visitErr, colly.ErrRobotsTxtBlocked) {
+		return fmt.Errorf("%w: %s", ErrDisallowed, link)
 	}
+	return fmt.Errorf("%w: %w", ErrSourceUnavailable, visitErr)
+}
+
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
 
-	// immediately cut out all trailing data from the space including the space
-	// eg. Hours
-	time, _, _ = strings.Cut(time, " ")
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
 
-	// if time contains "½", then add .5 to cleantime
-	if strings.Contains(time, "½") {
-		cleanTime = 0.5
-		time, _, _ = strings.Cut(time, "½")
-	}
+// only lets the request through once it is its turn at the site
+type limitTransport struct {
+	base http.RoundTripper
+}
 
-	// convert the whole number portion to a float 32
-	cleanTimeWhole, err := strconv.ParseFloat(time, 32)
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := waitForHost(req.Context(), req.URL.Hostname())
 	if err != nil {
-		log.Println("Error converting given Clean Time to Float", err)
+		return nil, err
 	}
+	defer release()
+
+	return t.base.RoundTrip(req)
+}
 
-	cleanTime = cleanTime + float32(cleanTimeWhole)
+// game with no data. all empty strings and numerical values as -1
+func emptyGame() (game Game) {
+	for _, category := range Categories {
+		game.SetTime(category, -1)
+	}
 	return
 }
+
+// true if the game has a time for any category
+func (game Game) HasTimes() bool {
+	for _, category := range Categories {
+		if game.Time(category) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// if the page had none of the times, mark them all as missing and return ErrNoTimeData
+func checkTimeData(game *Game, link string) error {
+	if !game.HasTimes() {
+		for _, category := range Categories {
+			game.SetTime(category, -1)
+		}
+		return fmt.Errorf("%w: %s", ErrNoTimeData, link)
+	}
+	return nil
+}
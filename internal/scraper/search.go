@@ -3,250 +3,219 @@ package scraper
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
-	"math/rand"
-	"regexp"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/chromedp/chromedp"
 )
 
-// given a name for a game, returns the link for the game
-// eg. /game/68151
-func searchHLTB(query string) (gameLink string) {
-	// Define a custom user agent
-	userAgent := getRandUserAgent()
-
-	// Set Chrome options for headless execution
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),    // Ensure Chrome runs headless
-		chromedp.Flag("disable-gpu", true), // Disable GPU to avoid issues
-		chromedp.Flag("no-sandbox", true),  // Required for some environments
-		chromedp.UserAgent(userAgent),      // Set custom user agent
-	)
-
-	// Create an allocator with the defined options
-	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	defer cancel()
-
-	// 3 second timeout in the event there is no game found from search
-	ctx, cancel := context.WithTimeout(allocCtx, 3*time.Second)
-	defer cancel()
-
-	// Create a new Chrome context
-	ctx, cancel = chromedp.NewContext(ctx)
-	defer cancel()
-
-	// Perform the search on HLTB
-	var pageHTML string
-	err := chromedp.Run(ctx,
-		chromedp.Navigate("https://www.howlongtobeat.com/?q="+query),
-		chromedp.WaitVisible(`.GameCard_inside_blur__cP8_l`, chromedp.ByQuery),
-		chromedp.OuterHTML("html", &pageHTML),
-	)
-	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			log.Println("No such game found within the timeout of 3 seconds!")
-			return ""
-		} else {
-			log.Println(err)
-			return ""
+// given a name for a game, returns the games found on HLTB ranked by how well they match
+// the search API is used first, and the results page is only loaded in the browser if the API fails
+func searchHLTB(ctx context.Context, query string) ([]Candidate, error) {
+	client := newHLTBClient()
+	results, err := client.Search(ctx, query)
+	if err == nil {
+		candidates := make([]Candidate, 0, len(results))
+		for _, result := range results {
+			candidates = append(candidates, result.candidate(client))
 		}
+		return rankCandidates(query, candidates), nil
 	}
 
-	// extract the link to the first game in the list
-	return extractLinkHLTB(pageHTML)
-}
-
-func extractLinkHLTB(pageHTML string) (gameLink string) {
-	// find the location where the first item from the search list is
-	firstindex := strings.Index(pageHTML, "GameCard_inside_blur__cP8_l")
-
-	// if there is no such item, then return empty string
-	if firstindex == -1 {
-		return ""
+	// the API answered, so the results page would not find the game either
+	if ctx.Err() != nil || errors.Is(err, ErrNoResults) {
+		return nil, err
 	}
-
-	// navigate to where `a href=` is in the next part of the string
-	ahrefindex := strings.Index(pageHTML[firstindex:], "a href=")
-
-	// cut off all trailing info
-	suffindex := strings.Index(pageHTML[firstindex+ahrefindex+7:], ">")
-
-	// grab the first link for game data that we find
-	// we add 8 to cut off the `a href="` from the search
-	// we also add 7 for the same reason as above, but also subtract 1
-	// so that it can remove the `"`
-	return pageHTML[firstindex+ahrefindex+8 : firstindex+ahrefindex+7+suffindex-1]
+	log.Println("Querying HLTB search API failed. Retrying in the browser:", err)
+	return searchHLTBBrowser(ctx, query)
 }
 
-// given a name for a game, returns the link for the game
-// eg. /Game/Details/3441
-func searchCompletionator(query string) (gameLink string) {
-	userAgent := getRandUserAgent()
-
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.UserAgent(userAgent),
-	)
+// given a name for a game, returns the games on the HLTB results page ranked by how well they match
+func searchHLTBBrowser(ctx context.Context, query string) ([]Candidate, error) {
+	base := CurrentBaseURLs().HLTB
+	searchURL := base + "/?q=" + url.QueryEscape(query)
 
-	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	defer cancel()
+	// a recent search is not made again
+	pageHTML, cached, err := cachedSearchPage(searchURL)
+	if err != nil {
+		return nil, err
+	}
+	if cached {
+		return rankedOrNoResults(query, ExtractCandidatesHLTB(pageHTML, base))
+	}
 
-	ctx, cancel := context.WithTimeout(allocCtx, 3*time.Second)
-	defer cancel()
+	// wait for a turn to use the site
+	release, err := waitForHost(ctx, hostOf(base))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
-	ctx, cancel = chromedp.NewContext(ctx)
+	// 3 second timeout in the event there is no game found from search
+	searchCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	var pageHTML string
-	err := chromedp.Run(ctx,
-		chromedp.Navigate("https://completionator.com/Game?keyword="+query+"&sortColumn=GameName&sortDirection=ASC"),
-		chromedp.WaitVisible(`.cgpager-results`, chromedp.ByQuery),
+	// Perform the search on HLTB
+	err = RunBrowser(searchCtx, searchURL,
+		waitForResults(CurrentSelectors().HLTB.Search),
 		chromedp.OuterHTML("html", &pageHTML),
 	)
 	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			log.Println("No such game found within the timeout of 3 seconds!")
-			return ""
-		} else {
-			log.Println(err)
-			return ""
-		}
+		return nil, searchError(ctx, err)
 	}
+	cacheSearchPage(searchURL, pageHTML)
 
-	return extractLinkCompletionator(pageHTML)
+	// rank every game in the list, not only the first one
+	return rankedOrNoResults(query, ExtractCandidatesHLTB(pageHTML, base))
 }
 
-func extractLinkCompletionator(pageHTML string) (gameLink string) {
-	// find the location where the first item from the search list is
-	firstindex := strings.Index(pageHTML, `tr class=" even"`) + 16
-
-	// navigate to where `a href=` is in the next part of the string (link is right after this)
-	ahrefindex := strings.Index(pageHTML[firstindex:], "a href=") + 8
+// every game card links to the page of the game. eg. /game/68151
+// the cards have no year or platforms, so those are left empty
+// base is the HLTB site the page came from
+func ExtractCandidatesHLTB(pageHTML string, base string) (candidates []Candidate) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(pageHTML))
+	if err != nil {
+		log.Println("Error parsing HLTB results page:", err)
+		return nil
+	}
 
-	// cut off all trailing info (link is before this)
-	suffindex := strings.Index(pageHTML[firstindex+ahrefindex:], ">") - 1
+	seen := map[string]bool{}
+	doc.Find(CurrentSelectors().HLTB.Search.Result).Each(func(_ int, a *goquery.Selection) {
+		href, _ := a.Attr("href")
+		if seen[href] {
+			return
+		}
 
-	// take the link between the two
-	return pageHTML[firstindex+ahrefindex : firstindex+ahrefindex+suffindex]
+		// the image and the name of a card both link to the game. only the name has text
+		title := strings.TrimSpace(a.AttrOr("title", a.Text()))
+		if title == "" {
+			return
+		}
+		seen[href] = true
+		candidates = append(candidates, Candidate{
+			Title: title,
+			URL:   base + href,
+		})
+	})
+	return
 }
 
-// searches Bing for game that failed HLTB query
-func searchBing(query string) (gameLink string) {
-	userAgent := getRandUserAgent()
-
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.UserAgent(userAgent),
-	)
+// given a name for a game, returns the games on the Completionator results page ranked by how well they match
+func searchCompletionator(ctx context.Context, query string) ([]Candidate, error) {
+	base := CurrentBaseURLs().Completionator
+	searchURL := base + "/Game?keyword=" + url.QueryEscape(query) + "&sortColumn=GameName&sortDirection=ASC"
 
-	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	defer cancel()
+	// a recent search is not made again
+	pageHTML, cached, err := cachedSearchPage(searchURL)
+	if err != nil {
+		return nil, err
+	}
+	if cached {
+		return rankedOrNoResults(query, ExtractCandidatesCompletionator(pageHTML, base))
+	}
 
-	ctx, cancel := context.WithTimeout(allocCtx, 3*time.Second)
-	defer cancel()
+	// wait for a turn to use the site
+	release, err := waitForHost(ctx, hostOf(base))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
-	ctx, cancel = chromedp.NewContext(ctx)
+	// 3 second timeout in the event there is no game found from search
+	searchCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	var pageHTML string
-	err := chromedp.Run(ctx,
-		// make bing search
-		chromedp.Navigate("https://www.bing.com/search?q=hltb+"+query),
-
-		// wait 0.5 seconds to grab data
-		chromedp.Sleep(500*time.Millisecond),
-
+	err = RunBrowser(searchCtx, searchURL,
+		waitForResults(CurrentSelectors().Completionator.Search),
 		chromedp.OuterHTML("html", &pageHTML),
 	)
 	if err != nil {
-		log.Println("err", err)
-		return ""
+		return nil, searchError(ctx, err)
 	}
+	cacheSearchPage(searchURL, pageHTML)
 
-	return extractLinkBing(pageHTML)
+	return rankedOrNoResults(query, ExtractCandidatesCompletionator(pageHTML, base))
 }
 
-// given the pageHTML, will scrape the first link and assume that it leads to the correct location for the correct game
-func extractLinkBing(pageHTML string) (gameLink string) {
-	// find the location where the first item from the search list is
-	firstindex := strings.Index(pageHTML, `ol id="b_results"`) + 17
-
-	// if there is no such item, then return empty string
-	if firstindex == -1 {
-		return ""
+// each row of the results table links to the page of the game. eg. /Game/Details/3441
+// the other cells of the row hold the platforms and the release date
+// base is the Completionator site the page came from
+func ExtractCandidatesCompletionator(pageHTML string, base string) (candidates []Candidate) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(pageHTML))
+	if err != nil {
+		log.Println("Error parsing Completionator results page:", err)
+		return nil
 	}
 
-	// INFO: if deeplinks does not exist, then must trim the bad stuff
-	// eg. `/completions`
-
-	// grab the link within the `href="` and `"`
-	ahrefindexstart := strings.Index(pageHTML[firstindex:], `href="`) + 6
-	ahrefindexend := strings.Index(pageHTML[firstindex+ahrefindexstart:], `"`)
-
-	gameLink = pageHTML[firstindex+ahrefindexstart : firstindex+ahrefindexstart+ahrefindexend]
+	search := CurrentSelectors().Completionator.Search
+	seen := map[string]bool{}
+	doc.Find(search.Row).Each(func(_ int, row *goquery.Selection) {
+		a := row.Find(search.Result).First()
+		href, ok := a.Attr("href")
+		if !ok || seen[href] {
+			return
+		}
+		seen[href] = true
 
-	// use REGEX to strip the link of what is undesired
-	// `https://howlongtobeat.com/game/####` is the desired string
+		candidate := Candidate{
+			Title: strings.TrimSpace(a.Text()),
+			URL:   base + href,
+		}
+		row.Find("td").Each(func(_ int, cell *goquery.Selection) {
+			// skip the cell with the name so a year in the name is not taken as the release date
+			if cell.Find(search.Result).Length() > 0 {
+				return
+			}
+			if year := parseYear(cell.Text()); year != 0 && candidate.Year == 0 {
+				candidate.Year = year
+			}
+			if search.Platform == "" {
+				return
+			}
+			cell.Find(search.Platform).Each(func(_ int, platform *goquery.Selection) {
+				candidate.Platforms = append(candidate.Platforms, strings.TrimSpace(platform.Text()))
+			})
+		})
+		candidates = append(candidates, candidate)
+	})
+	return
+}
 
-	pattern := `https:\/\/howlongtobeat\.com\/game\/[0-9]+`
-	re := regexp.MustCompile(pattern)
-	gameLink = re.FindString(gameLink)
+// waits until the results of a search are shown
+// sites with no element to wait for are given 0.5 seconds instead
+func waitForResults(search SearchSelectors) chromedp.Action {
+	if search.Ready == "" {
+		return chromedp.Sleep(500 * time.Millisecond)
+	}
+	return chromedp.WaitVisible(search.Ready, chromedp.ByQuery)
+}
 
-	return gameLink
+// error for a failed search in the browser
+// a search that ran out of time found no game, so it is not a failure of the site
+func searchError(ctx context.Context, err error) error {
+	// the caller cancelled the search, so it is not a failure of the site
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Println("No such game found within the timeout of 3 seconds!")
+		return ErrNoResults
+	}
+	log.Println(err)
+	if errors.Is(err, ErrDisallowed) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrSourceUnavailable, err)
 }
 
-func getRandUserAgent() string {
-	userAgents := []string{
-		// chrome
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
-		"Mozilla/5.0 (Windows NT 10.0; WOW64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36",
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 11_2_3) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_14_6) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36",
-		"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
-		"Mozilla/5.0 (X11; Ubuntu; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36",
-		"Mozilla/5.0 (X11; Linux i686) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36",
-
-		// firefox
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:120.0) Gecko/20100101 Firefox/120.0",
-		"Mozilla/5.0 (Windows NT 10.0; WOW64; rv:119.0) Gecko/20100101 Firefox/119.0",
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:118.0) Gecko/20100101 Firefox/118.0",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:120.0) Gecko/20100101 Firefox/120.0",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 11_2_3; rv:119.0) Gecko/20100101 Firefox/119.0",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_14_6; rv:118.0) Gecko/20100101 Firefox/118.0",
-		"Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0",
-		"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:119.0) Gecko/20100101 Firefox/119.0",
-		"Mozilla/5.0 (X11; Linux i686; rv:118.0) Gecko/20100101 Firefox/118.0",
-
-		// safari
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Version/17.0 Safari/537.36",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 11_2_3) AppleWebKit/537.36 (KHTML, like Gecko) Version/16.0 Safari/537.36",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_14_6) AppleWebKit/537.36 (KHTML, like Gecko) Version/15.0 Safari/537.36",
-		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/537.36 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/537.36",
-		"Mozilla/5.0 (iPad; CPU OS 16_2 like Mac OS X) AppleWebKit/537.36 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/537.36",
-		"Mozilla/5.0 (iPhone; CPU iPhone OS 15_1 like Mac OS X) AppleWebKit/537.36 (KHTML, like Gecko) Version/15.0 Mobile/15E148 Safari/537.36",
-
-		// edge
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36 Edg/119.0.0.0",
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36 Edg/118.0.0.0",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 11_2_3) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36 Edg/119.0.0.0",
-
-		// mobile
-		"Mozilla/5.0 (Linux; Android 13; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
-		"Mozilla/5.0 (Linux; Android 12; Samsung Galaxy S21) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Mobile Safari/537.36",
-		"Mozilla/5.0 (Linux; Android 11; OnePlus 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Mobile Safari/537.36",
-		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/537.36 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/537.36",
-		"Mozilla/5.0 (iPhone; CPU iPhone OS 16_2 like Mac OS X) AppleWebKit/537.36 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/537.36",
-	}
-	return userAgents[rand.Intn(len(userAgents))]
+// ranks the candidates against the query. a results page with none on it gives ErrNoResults
+func rankedOrNoResults(query string, candidates []Candidate) ([]Candidate, error) {
+	if len(candidates) == 0 {
+		return nil, ErrNoResults
+	}
+	return rankCandidates(query, candidates), nil
 }
@@ -0,0 +1,293 @@
+package scraper
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gocolly/colly"
+	"gopkg.in/yaml.v3"
+)
+
+// the selectors shipped with the app. written to the config dir so users can change them
+//
+//go:embed selectors.yaml
+var defaultSelectorsYAML []byte
+
+// where the scraper finds the games and their times on each site
+type Selectors struct {
+	Version        int             `yaml:"version"`
+	HLTB           SiteSelectors   `yaml:"hltb"`
+	Completionator SiteSelectors   `yaml:"completionator"`
+	Bing           SearchSelectors `yaml:"bing"`
+	DuckDuckGo     SearchSelectors `yaml:"duckduckgo"`
+	SearXNG        SearchSelectors `yaml:"searxng"`
+}
+
+// selectors for the search results page and the page of a game on a site
+type SiteSelectors struct {
+	Search SearchSelectors `yaml:"search"`
+	Game   PageSelectors   `yaml:"game"`
+}
+
+// where the games found are on a search results page
+type SearchSelectors struct {
+	// path of the JSON search endpoint of the site. empty loads the results page in the browser instead
+	API string `yaml:"api,omitempty"`
+	// element only shown once the results have loaded. empty waits a fixed time instead
+	Ready string `yaml:"ready,omitempty"`
+	// element holding one game. empty when the links are not grouped (eg. HLTB cards)
+	Row string `yaml:"row,omitempty"`
+	// link to the page of a game
+	Result string `yaml:"result"`
+	// links to the platforms of the game inside of a row
+	Platform string `yaml:"platform,omitempty"`
+}
+
+// where the name and times are on the page of a game
+type PageSelectors struct {
+	Name  string `yaml:"name"`
+	Times string `yaml:"times"`
+	Item  string `yaml:"item"`
+	Label string `yaml:"label"`
+	Value string `yaml:"value"`
+
+	// label shown on the site -> category of time. labels not listed are skipped
+	Labels map[string]string `yaml:"labels"`
+
+	// details of the game, each in an element that starts with its label
+	// eg. <div><strong>Genres:</strong> Platform</div>
+	Info      string `yaml:"info,omitempty"`
+	InfoLabel string `yaml:"infoLabel,omitempty"`
+	// label shown on the site -> detail of the game. labels not listed are skipped
+	Fields map[string]string `yaml:"fields,omitempty"`
+	// image of the cover of the game
+	Cover string `yaml:"cover,omitempty"`
+
+	// table with the number of submissions and spread of each category of time. nil if the site has none
+	Stats *StatsSelectors `yaml:"stats,omitempty"`
+
+	// table with the times on each platform. nil if the site has none
+	Platforms *PlatformSelectors `yaml:"platforms,omitempty"`
+}
+
+// where the number of submissions and spread of each category of time are in a table
+// each row starts with a cell holding the label of the category, which is found in the labels of the page
+type StatsSelectors struct {
+	Table string `yaml:"table"`
+	Row   string `yaml:"row"`
+	Cell  string `yaml:"cell"`
+
+	// position of each value in a row, counting the label as 0. 0 when the table does not show the value
+	Polled int `yaml:"polled"`
+	Median int `yaml:"median"`
+	Low    int `yaml:"low"`
+	High   int `yaml:"high"`
+}
+
+// where the times on each platform are in a table
+// each row starts with a cell holding the name of the platform. eg. "PC" or "Nintendo Switch"
+type PlatformSelectors struct {
+	Table string `yaml:"table"`
+	Row   string `yaml:"row"`
+	Cell  string `yaml:"cell"`
+
+	// position of each value in a row, counting the platform as 0. 0 when the table does not show the value
+	Polled int `yaml:"polled"`
+	// category of time -> position of its cell
+	Times map[string]int `yaml:"times"`
+}
+
+var (
+	selectorsMu sync.RWMutex
+	selectors   = mustParseSelectors(defaultSelectorsYAML)
+)
+
+// loads the selectors from the YAML file at path and uses them for every search and fetch
+// if the file does not exist, it is created with the default selectors
+// if the file is from an older version of the app, it is saved as path+".old" and replaced with the default selectors
+// if the file cannot be used, the default selectors are kept and the error is returned
+func LoadSelectors(path string) error {
+	defaults := mustParseSelectors(defaultSelectorsYAML)
+
+	log.Println("Loading selectors from yaml file:", path)
+	filedata, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Println("Selectors file DNE. Creating it with the default selectors")
+		SetSelectors(defaults)
+		return writeDefaultSelectors(path)
+	}
+	if err != nil {
+		SetSelectors(defaults)
+		return fmt.Errorf("error reading selectors file: %w", err)
+	}
+
+	// an older file is replaced even when it is missing selectors the app now needs
+	var versioned struct {
+		Version int `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(filedata, &versioned); err == nil && versioned.Version < defaults.Version {
+		log.Println("Selectors file is version", versioned.Version, "but the app has version", defaults.Version, ". Replacing it")
+		SetSelectors(defaults)
+		if err := os.Rename(path, path+".old"); err != nil {
+			return fmt.Errorf("error saving old selectors file: %w", err)
+		}
+		return writeDefaultSelectors(path)
+	}
+
+	loaded, err := parseSelectors(filedata)
+	if err != nil {
+		SetSelectors(defaults)
+		return fmt.Errorf("error loading selectors from %s: %w", path, err)
+	}
+
+	SetSelectors(loaded)
+	return nil
+}
+
+// uses the given selectors for every search and fetch started after this call
+func SetSelectors(s Selectors) {
+	selectorsMu.Lock()
+	defer selectorsMu.Unlock()
+	selectors = s
+}
+
+// returns the selectors that are currently used
+func CurrentSelectors() Selectors {
+	selectorsMu.RLock()
+	defer selectorsMu.RUnlock()
+	return selectors
+}
+
+func writeDefaultSelectors(path string) error {
+	if err := os.WriteFile(path, defaultSelectorsYAML, 0644); err != nil {
+		return fmt.Errorf("error writing default selectors file: %w", err)
+	}
+	return nil
+}
+
+// the default selectors are part of the app, so a problem with them is a bug
+func mustParseSelectors(data []byte) Selectors {
+	s, err := parseSelectors(data)
+	if err != nil {
+		panic("default selectors are invalid: " + err.Error())
+	}
+	return s
+}
+
+// extracts the selectors from the yaml and checks that none of the required ones are missing
+func parseSelectors(data []byte) (s Selectors, err error) {
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("%w: %w", ErrInvalidSelectors, err)
+	}
+
+	var errs []error
+	errs = append(errs, s.HLTB.validate("hltb")...)
+	errs = append(errs, s.Completionator.validate("completionator")...)
+	// the platforms and release date of a game are found in its row of the results table
+	if s.Completionator.Search.Row == "" {
+		errs = append(errs, fmt.Errorf("%w: completionator.search.row is missing", ErrInvalidSelectors))
+	}
+	searches := []struct {
+		name   string
+		search SearchSelectors
+	}{{"bing", s.Bing}, {"duckduckgo", s.DuckDuckGo}, {"searxng", s.SearXNG}}
+	for _, ws := range searches {
+		if ws.search.Result == "" {
+			errs = append(errs, fmt.Errorf("%w: %s.result is missing", ErrInvalidSelectors, ws.name))
+		}
+	}
+	return s, errors.Join(errs...)
+}
+
+func (site SiteSelectors) validate(name string) (errs []error) {
+	required := []struct{ field, value string }{
+		{"search.result", site.Search.Result},
+		{"game.name", site.Game.Name},
+		{"game.times", site.Game.Times},
+		{"game.item", site.Game.Item},
+		{"game.label", site.Game.Label},
+		{"game.value", site.Game.Value},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			errs = append(errs, fmt.Errorf("%w: %s.%s is missing", ErrInvalidSelectors, name, r.field))
+		}
+	}
+
+	if len(site.Game.Labels) == 0 {
+		errs = append(errs, fmt.Errorf("%w: %s.game.labels is empty", ErrInvalidSelectors, name))
+	}
+	for label, category := range site.Game.Labels {
+		if !slices.Contains(Categories, category) {
+			errs = append(errs, fmt.Errorf("%w: %s label %q has unknown category %q", ErrInvalidSelectors, name, label, category))
+		}
+	}
+
+	// details are optional, but a label is needed to know which detail an element holds
+	if len(site.Game.Fields) != 0 && (site.Game.Info == "" || site.Game.InfoLabel == "") {
+		errs = append(errs, fmt.Errorf("%w: %s.game.info and infoLabel are needed for fields", ErrInvalidSelectors, name))
+	}
+	if stats := site.Game.Stats; stats != nil && (stats.Table == "" || stats.Row == "" || stats.Cell == "") {
+		errs = append(errs, fmt.Errorf("%w: %s.game.stats needs a table, row and cell", ErrInvalidSelectors, name))
+	}
+	if platforms := site.Game.Platforms; platforms != nil {
+		if platforms.Table == "" || platforms.Row == "" || platforms.Cell == "" {
+			errs = append(errs, fmt.Errorf("%w: %s.game.platforms needs a table, row and cell", ErrInvalidSelectors, name))
+		}
+		for category := range platforms.Times {
+			if !slices.Contains(PlatformCategories, category) {
+				errs = append(errs, fmt.Errorf("%w: %s.game.platforms has unknown category %q", ErrInvalidSelectors, name, category))
+			}
+		}
+	}
+	for label, field := range site.Game.Fields {
+		if !slices.Contains(Fields, field) {
+			errs = append(errs, fmt.Errorf("%w: %s label %q has unknown field %q", ErrInvalidSelectors, name, label, field))
+		}
+	}
+	return
+}
+
+// returns the category of time for the label shown on the site. capitalization is ignored
+func (page PageSelectors) category(label string) (string, bool) {
+	label = strings.TrimSpace(label)
+	for l, category := range page.Labels {
+		if strings.EqualFold(l, label) {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+// writes each time inside of the container e to the game
+// when several labels give the same category, the highest time is kept
+// categories with no time on the page are left as -1
+func (page PageSelectors) readTimes(e *colly.HTMLElement, game *Game) {
+	e.ForEach(page.Item, func(_ int, el *colly.HTMLElement) {
+		category, ok := page.category(el.ChildText(page.Label))
+		if !ok {
+			return
+		}
+		raw := el.ChildText(page.Value)
+		if value := CleanTime(raw); value > game.Time(category) {
+			game.SetTime(category, value)
+			if game.Raw == nil {
+				game.Raw = map[string]string{}
+			}
+			game.Raw[category] = raw
+		}
+	})
+
+	// when finished obtaining all the data, fill all empty values with "-1"
+	for _, category := range Categories {
+		if game.Time(category) == 0 {
+			game.SetTime(category, -1)
+		}
+	}
+}
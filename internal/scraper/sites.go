@@ -0,0 +1,112 @@
+package scraper
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// where each site is reached. eg. "https://howlongtobeat.com" with no trailing slash
+// these can be pointed at another server, such as a local copy of the site used by the tests
+type BaseURLs struct {
+	HLTB           string
+	Completionator string
+	Bing           string
+	DuckDuckGo     string
+	// there is no public instance used by default, so searching SearXNG is skipped until this is set
+	SearXNG string
+}
+
+// the real sites. used until SetBaseURLs is called
+var DefaultBaseURLs = BaseURLs{
+	HLTB:           "https://howlongtobeat.com",
+	Completionator: "https://completionator.com",
+	Bing:           "https://www.bing.com",
+	DuckDuckGo:     "https://html.duckduckgo.com",
+}
+
+var (
+	baseURLsMu sync.RWMutex
+	baseURLs   = DefaultBaseURLs
+)
+
+// sets where each site is reached for every search and fetch started after this call
+// empty fields are set to the real site
+func SetBaseURLs(urls BaseURLs) {
+	urls.HLTB = baseOrDefault(urls.HLTB, DefaultBaseURLs.HLTB)
+	urls.Completionator = baseOrDefault(urls.Completionator, DefaultBaseURLs.Completionator)
+	urls.Bing = baseOrDefault(urls.Bing, DefaultBaseURLs.Bing)
+	urls.DuckDuckGo = baseOrDefault(urls.DuckDuckGo, DefaultBaseURLs.DuckDuckGo)
+	urls.SearXNG = strings.TrimSuffix(strings.TrimSpace(urls.SearXNG), "/")
+
+	baseURLsMu.Lock()
+	defer baseURLsMu.Unlock()
+	baseURLs = urls
+	resetRobots()
+}
+
+// returns where each site is currently reached
+func CurrentBaseURLs() BaseURLs {
+	baseURLsMu.RLock()
+	defer baseURLsMu.RUnlock()
+	return baseURLs
+}
+
+func baseOrDefault(base string, dflt string) string {
+	if base == "" {
+		return dflt
+	}
+	return strings.TrimSuffix(base, "/")
+}
+
+// host that requests to the base url wait for their turn at
+func hostOf(base string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base
+	}
+	return u.Hostname()
+}
+
+// matches links to the page of a game on HLTB. eg. `https://howlongtobeat.com/game/68151`
+// the links of the site with and without "www." are both matched, and the id of the game is captured
+func hltbGameLink(base string) *regexp.Regexp {
+	return gameLink(base, `/game/([0-9]+)`)
+}
+
+// matches links to the page of a game on Completionator. eg. `https://completionator.com/Game/Details/3441`
+// the id of the game is captured
+func completionatorGameLink(base string) *regexp.Regexp {
+	return gameLink(base, `(?i:/Game/Details/)([0-9]+)`)
+}
+
+// the link to the page of the game on HLTB at base that link points to
+// links to other pages of the game (eg. /game/42818/completions) give the page of the game itself
+func hltbGameURL(base string, link string) (string, bool) {
+	match := hltbGameLink(base).FindStringSubmatch(strings.TrimSpace(link))
+	if match == nil {
+		return "", false
+	}
+	return base + "/game/" + match[len(match)-1], true
+}
+
+// the link to the page of the game on Completionator at base that link points to
+func completionatorGameURL(base string, link string) (string, bool) {
+	match := completionatorGameLink(base).FindStringSubmatch(strings.TrimSpace(link))
+	if match == nil {
+		return "", false
+	}
+	return base + "/Game/Details/" + match[len(match)-1], true
+}
+
+// matches links on the site at base whose path matches the pattern
+// the links of the site over http and https, and with and without "www." are all matched
+func gameLink(base string, path string) *regexp.Regexp {
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "" {
+		return regexp.MustCompile(regexp.QuoteMeta(base) + path)
+	}
+	host := strings.TrimPrefix(u.Host, "www.")
+	return regexp.MustCompile(`https?://(www\.)?` + regexp.QuoteMeta(host) + path)
+}
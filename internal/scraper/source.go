@@ -0,0 +1,175 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/EZRA-DVLPR/GameList/internal/normalize"
+)
+
+// value of the search source preference that means every registered source is used
+const AllSources = "All"
+
+// a site that completion times can be searched for and fetched from
+type Source interface {
+	// name shown in the settings and saved as the search source preference. eg. "HLTB"
+	Name() string
+
+	// searches only the site for the game name and returns the games found, best match first
+	// fetching the URL of a candidate gives its data. stops early with ctx.Err() once ctx is cancelled
+	// SearchCandidates and SearchGame search a source with the web search fallback
+	Candidates(ctx context.Context, gameName string) ([]Candidate, error)
+
+	// fetches the data of the game from the entire proper link to its page on the site
+	Fetch(ctx context.Context, link string) (Game, error)
+
+	// link to the page on this site that is saved in the game
+	URL(game Game) string
+
+	// saves the link to the page on this site in the game
+	SetURL(game *Game, link string)
+
+	// the proper link to the page of the game that link points to on this site
+	// false if link is not the page of a game on this site
+	GameURL(link string) (string, bool)
+}
+
+// all sources in the order they were registered
+var registry []Source
+
+// built in sources. the order here is the order they are shown and searched in
+func init() {
+	Register(hltbSource{})
+	Register(completionatorSource{})
+}
+
+// adds a source so it can be searched and selected in the settings
+// registering two sources with the same name is a programming error
+func Register(src Source) {
+	if _, exists := GetSource(src.Name()); exists {
+		panic(fmt.Sprintf("scraper: source %q registered twice", src.Name()))
+	}
+	registry = append(registry, src)
+}
+
+// returns every registered source in registration order
+func Sources() []Source {
+	return append([]Source(nil), registry...)
+}
+
+// returns the names of every registered source in registration order
+func SourceNames() (names []string) {
+	for _, src := range registry {
+		names = append(names, src.Name())
+	}
+	return
+}
+
+// finds the registered source with the given name
+func GetSource(name string) (Source, bool) {
+	for _, src := range registry {
+		if src.Name() == name {
+			return src, true
+		}
+	}
+	return nil, false
+}
+
+// searches the site of src for the game name and returns the games found, best match first
+// if that fails and src implements WebSearchable, then the enabled web searches are searched for the games on its site
+func SearchCandidates(ctx context.Context, src Source, gameName string) ([]Candidate, error) {
+	candidates, err := src.Candidates(ctx, gameName)
+	// a cancelled search should not fall back to another search
+	if err == nil || ctx.Err() != nil {
+		return candidates, err
+	}
+	ws, ok := src.(WebSearchable)
+	if !ok {
+		return nil, err
+	}
+
+	log.Printf("Querying %s Failed. Retrying through web search...\n", src.Name())
+	candidates, webErr := searchWebFallback(ctx, ws, normalize.Query(gameName))
+	if webErr != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Println("No Link found. Process Aborted!")
+		return nil, fmt.Errorf("%w; web search: %w", err, webErr)
+	}
+	return candidates, nil
+}
+
+// searches for the game name like SearchCandidates, then fetches the data of the game that best matches it
+func SearchGame(ctx context.Context, src Source, gameName string) (Game, error) {
+	candidates, err := SearchCandidates(ctx, src, gameName)
+	if err != nil {
+		return emptyGame(), err
+	}
+
+	log.Println("Link obtained. Web Scraping process beginning...")
+	return src.Fetch(ctx, candidates[0].URL)
+}
+
+// HowLongToBeat
+type hltbSource struct{}
+
+func (hltbSource) Name() string { return "HLTB" }
+func (hltbSource) Candidates(ctx context.Context, gameName string) ([]Candidate, error) {
+	return SearchCandidatesHLTB(ctx, gameName)
+}
+func (hltbSource) Fetch(ctx context.Context, link string) (Game, error) {
+	return FetchHLTB(ctx, link)
+}
+func (hltbSource) URL(game Game) string           { return game.HLTBUrl }
+func (hltbSource) SetURL(game *Game, link string) { game.HLTBUrl = link }
+func (hltbSource) GameURL(link string) (string, bool) {
+	return hltbGameURL(CurrentBaseURLs().HLTB, link)
+}
+func (hltbSource) WebSearchTerms() string { return "howlongtobeat" }
+func (hltbSource) BaseURL() string        { return CurrentBaseURLs().HLTB }
+
+// the title of the page on HLTB is "How long is Celeste? | HowLongToBeat"
+func (hltbSource) WebResult(base string, href string, title string) (Candidate, bool) {
+	link, ok := hltbGameURL(base, href)
+	if !ok {
+		return Candidate{}, false
+	}
+	title = strings.TrimPrefix(title, "How long is ")
+	if i := strings.LastIndex(title, "?"); i != -1 {
+		title = title[:i]
+	}
+	return Candidate{Title: strings.TrimSpace(title), URL: link}, true
+}
+
+// Completionator
+type completionatorSource struct{}
+
+func (completionatorSource) Name() string { return "Completionator" }
+func (completionatorSource) Candidates(ctx context.Context, gameName string) ([]Candidate, error) {
+	return SearchCandidatesCompletionator(ctx, gameName)
+}
+func (completionatorSource) Fetch(ctx context.Context, link string) (Game, error) {
+	return FetchCompletionator(ctx, link)
+}
+func (completionatorSource) URL(game Game) string           { return game.CompletionatorUrl }
+func (completionatorSource) SetURL(game *Game, link string) { game.CompletionatorUrl = link }
+func (completionatorSource) GameURL(link string) (string, bool) {
+	return completionatorGameURL(CurrentBaseURLs().Completionator, link)
+}
+func (completionatorSource) WebSearchTerms() string { return "completionator" }
+func (completionatorSource) BaseURL() string        { return CurrentBaseURLs().Completionator }
+
+// the title of the page on Completionator is "Celeste - Completionator"
+func (completionatorSource) WebResult(base string, href string, title string) (Candidate, bool) {
+	link, ok := completionatorGameURL(base, href)
+	if !ok {
+		return Candidate{}, false
+	}
+	if i := strings.LastIndex(title, " - "); i != -1 {
+		title = title[:i]
+	}
+	return Candidate{Title: strings.TrimSpace(title), URL: link}, true
+}
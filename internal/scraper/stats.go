@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly"
+)
+
+// adds a callback to c that writes the number of submissions and spread of each time to the game
+// when several rows give the same category (eg. "Single-Player" and "Co-Op"), the one with the most submissions is kept
+func (page PageSelectors) readStats(c *colly.Collector, game *Game) {
+	stats := page.Stats
+	if stats == nil {
+		return
+	}
+
+	c.OnHTML(stats.Table, func(e *colly.HTMLElement) {
+		e.DOM.Find(stats.Row).Each(func(_ int, row *goquery.Selection) {
+			cells := row.Find(stats.Cell)
+			category, ok := page.category(cells.Eq(0).Text())
+			if !ok {
+				return
+			}
+
+			polled := 0
+			if stats.Polled > 0 {
+				polled = parseCount(cells.Eq(stats.Polled).Text())
+			}
+			if game.Polled == nil {
+				game.Polled = map[string]int{}
+			}
+			if _, seen := game.Polled[category]; seen && polled <= game.Polled[category] {
+				return
+			}
+			game.Polled[category] = polled
+
+			if game.Ranges == nil {
+				game.Ranges = map[string]TimeRange{}
+			}
+			game.Ranges[category] = TimeRange{
+				Low:    statTime(cells, stats.Low),
+				Median: statTime(cells, stats.Median),
+				High:   statTime(cells, stats.High),
+			}
+		})
+	})
+}
+
+// the time in the cell at position i of the row. -1 if the table does not show it
+func statTime(cells *goquery.Selection, i int) float32 {
+	if i <= 0 {
+		return -1
+	}
+	return CleanTime(cells.Eq(i).Text())
+}
+
+// reads a count as the sites show it. eg. "1,234" gives 1234 and "3.2K" gives 3200
+// a count that cannot be read gives 0, the same as a site that does not report it
+func parseCount(text string) int {
+	text = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(text), ",", ""))
+
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(text, "K"):
+		multiplier = 1e3
+	case strings.HasSuffix(text, "M"):
+		multiplier = 1e6
+	}
+	text = strings.TrimRight(text, "KM")
+
+	count, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0
+	}
+	return int(count * multiplier)
+}
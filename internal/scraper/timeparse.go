@@ -0,0 +1,112 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// the text shown by a site could not be read as a time
+var errUnreadableTime = errors.New("unreadable time")
+
+// glyphs sites use for parts of an hour. eg. "12½ Hours"
+var fractionGlyphs = map[rune]float64{
+	'¼': 0.25,
+	'⅓': 1.0 / 3,
+	'½': 0.5,
+	'⅔': 2.0 / 3,
+	'¾': 0.75,
+}
+
+var (
+	// eg. "1:30" or "1:30:15"
+	clockPattern = regexp.MustCompile(`^(\d+):([0-5]?\d)(?::([0-5]?\d))?$`)
+
+	// a number, an optional glyph, and an optional unit. eg. "12½ Hours", "1h", "30 mins"
+	amountPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)?([¼⅓½⅔¾])?\s*([a-z]+)?`)
+
+	// comma between thousands. eg. "1,250 Hours"
+	thousandsPattern = regexp.MustCompile(`(\d),(\d{3})\b`)
+)
+
+// converts a time shown on a site to hours. eg. "12½ Hours" gives 12.5 and "45 Mins" gives 0.75
+// "--" means no one submitted a time and gives -1, as does text that cannot be read
+func CleanTime(time string) float32 {
+	hours, err := ParseTime(time)
+	if err != nil {
+		log.Println("Error converting given Clean Time to Float:", err)
+		return -1
+	}
+	return hours
+}
+
+// converts a time shown on a site to hours
+// understands hours, minutes and seconds in any mix ("1h 30m", "45 Mins", "2 Hours 5 Minutes"),
+// clock times ("1:30"), and glyphs for parts of an hour ("12½ Hours")
+// amounts with no unit are hours. "--" or empty text gives -1 with no error
+func ParseTime(time string) (float32, error) {
+	text := strings.ToLower(strings.TrimSpace(time))
+	if text == "" || text == "--" {
+		return -1, nil
+	}
+
+	if match := clockPattern.FindStringSubmatch(text); match != nil {
+		h, _ := strconv.Atoi(match[1])
+		m, _ := strconv.Atoi(match[2])
+		s, _ := strconv.Atoi(match[3]) // empty when there are no seconds
+		return float32(float64(h) + float64(m)/60 + float64(s)/3600), nil
+	}
+
+	text = thousandsPattern.ReplaceAllString(text, "$1$2")
+
+	var hours float64
+	found := false
+	for _, match := range amountPattern.FindAllStringSubmatch(text, -1) {
+		number, glyph, unit := match[1], match[2], match[3]
+		if number == "" && glyph == "" {
+			// a word with no amount, such as the "and" in "1 hour and 5 minutes"
+			continue
+		}
+
+		var amount float64
+		if number != "" {
+			n, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return -1, fmt.Errorf("%w: %q", errUnreadableTime, time)
+			}
+			amount = n
+		}
+		if glyph != "" {
+			amount += fractionGlyphs[[]rune(glyph)[0]]
+		}
+
+		perHour, err := unitsPerHour(unit)
+		if err != nil {
+			return -1, fmt.Errorf("%w: %q", err, time)
+		}
+		hours += amount / perHour
+		found = true
+	}
+
+	if !found {
+		return -1, fmt.Errorf("%w: %q", errUnreadableTime, time)
+	}
+	return float32(hours), nil
+}
+
+// how many of the unit make up an hour. no unit is hours
+func unitsPerHour(unit string) (float64, error) {
+	switch {
+	case unit == "", unit == "h", strings.HasPrefix(unit, "hr"), strings.HasPrefix(unit, "hour"):
+		return 1, nil
+	case unit == "m", strings.HasPrefix(unit, "min"):
+		return 60, nil
+	case unit == "s", strings.HasPrefix(unit, "sec"):
+		return 3600, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown unit %q", errUnreadableTime, unit)
+	}
+}
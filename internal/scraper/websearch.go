@@ -0,0 +1,261 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chromedp/chromedp"
+)
+
+// names of the built in web searches
+const (
+	WebSearchBing       = "Bing"
+	WebSearchDuckDuckGo = "DuckDuckGo"
+	WebSearchSearXNG    = "SearXNG"
+)
+
+// web searches used until SetWebSearches is called
+var DefaultWebSearches = []string{WebSearchBing}
+
+// a search engine that is used to find the page of a game when the search of its site fails
+type WebSearch interface {
+	// name shown in the settings. eg. "Bing"
+	Name() string
+
+	// link to the results page for the query. empty when the engine is not set up (eg. no SearXNG instance)
+	SearchURL(query string) string
+
+	// where the results are on the results page
+	Selectors() SearchSelectors
+
+	// the link a result points to. some engines link to a redirect of their own with the real link inside
+	ResultLink(href string) string
+}
+
+// a source whose games can be found with a web search
+type WebSearchable interface {
+	Source
+
+	// words added to the query so the engine finds pages of the site. eg. "howlongtobeat"
+	WebSearchTerms() string
+
+	// where the site is currently reached
+	BaseURL() string
+
+	// the game the result links to if href is the page of a game on the site at base
+	// the title of the game is taken from the title of the result, without the name of the site
+	WebResult(base string, href string, title string) (Candidate, bool)
+}
+
+var (
+	// all web searches in the order they were registered
+	webSearchRegistry []WebSearch
+
+	webSearchesMu sync.RWMutex
+	webSearches   = DefaultWebSearches
+)
+
+// built in web searches. the order here is the order they are shown and tried in
+func init() {
+	RegisterWebSearch(bingSearch{})
+	RegisterWebSearch(duckDuckGoSearch{})
+	RegisterWebSearch(searxngSearch{})
+}
+
+// adds a web search so it can be enabled in the settings
+// registering two web searches with the same name is a programming error
+func RegisterWebSearch(ws WebSearch) {
+	if _, exists := GetWebSearch(ws.Name()); exists {
+		panic(fmt.Sprintf("scraper: web search %q registered twice", ws.Name()))
+	}
+	webSearchRegistry = append(webSearchRegistry, ws)
+}
+
+// returns the names of every registered web search in registration order
+func WebSearchNames() (names []string) {
+	for _, ws := range webSearchRegistry {
+		names = append(names, ws.Name())
+	}
+	return
+}
+
+// finds the registered web search with the given name
+func GetWebSearch(name string) (WebSearch, bool) {
+	for _, ws := range webSearchRegistry {
+		if ws.Name() == name {
+			return ws, true
+		}
+	}
+	return nil, false
+}
+
+// sets the web searches tried when the search of a site fails. unknown names are skipped
+// they are always tried in the order they were registered. none turns the fallback off
+func SetWebSearches(names []string) {
+	webSearchesMu.Lock()
+	defer webSearchesMu.Unlock()
+	webSearches = slices.Clone(names)
+}
+
+// returns the names of the web searches tried when the search of a site fails
+func CurrentWebSearches() []string {
+	webSearchesMu.RLock()
+	defer webSearchesMu.RUnlock()
+	return slices.Clone(webSearches)
+}
+
+// the enabled web searches in registration order
+func enabledWebSearches() (enabled []WebSearch) {
+	names := CurrentWebSearches()
+	for _, ws := range webSearchRegistry {
+		if slices.Contains(names, ws.Name()) {
+			enabled = append(enabled, ws)
+		}
+	}
+	return
+}
+
+// searches the enabled web searches in turn for the game on the site of src, until one finds it
+// used when the search of the site itself fails
+func searchWebFallback(ctx context.Context, src WebSearchable, query string) ([]Candidate, error) {
+	engines := enabledWebSearches()
+	if len(engines) == 0 {
+		return nil, fmt.Errorf("%w: no web search is enabled", ErrNoResults)
+	}
+
+	var errs []error
+	for _, ws := range engines {
+		log.Printf("Searching %s for game on %s: %s\n", ws.Name(), src.Name(), query)
+		candidates, err := searchWeb(ctx, ws, src, query)
+		if err == nil {
+			return candidates, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Println("Searching", ws.Name(), "failed:", err)
+		errs = append(errs, fmt.Errorf("%s: %w", ws.Name(), err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// searches the engine for the game on the site of src and returns the games found ranked by how well they match
+func searchWeb(ctx context.Context, ws WebSearch, src WebSearchable, query string) ([]Candidate, error) {
+	searchURL := ws.SearchURL(src.WebSearchTerms() + " " + query)
+	if searchURL == "" {
+		return nil, fmt.Errorf("%w: %s is not set up", ErrSourceUnavailable, ws.Name())
+	}
+	base := src.BaseURL()
+
+	// a recent search is not made again
+	pageHTML, cached, err := cachedSearchPage(searchURL)
+	if err != nil {
+		return nil, err
+	}
+	if cached {
+		return rankedOrNoResults(query, ExtractCandidatesWeb(pageHTML, ws, src, base))
+	}
+
+	// wait for a turn to use the site
+	u, err := url.Parse(searchURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing link %s: %w", searchURL, err)
+	}
+	release, err := waitForHost(ctx, u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// 3 second timeout in the event there is no game found from search
+	searchCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	err = RunBrowser(searchCtx, searchURL,
+		// wait for the results to grab data
+		waitForResults(ws.Selectors()),
+
+		chromedp.OuterHTML("html", &pageHTML),
+	)
+	if err != nil {
+		return nil, searchError(ctx, err)
+	}
+	cacheSearchPage(searchURL, pageHTML)
+
+	return rankedOrNoResults(query, ExtractCandidatesWeb(pageHTML, ws, src, base))
+}
+
+// every result of the web search that links to the page of a game on the site of src
+// base is the site of src that the results should link to
+func ExtractCandidatesWeb(pageHTML string, ws WebSearch, src WebSearchable, base string) (candidates []Candidate) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(pageHTML))
+	if err != nil {
+		log.Println("Error parsing", ws.Name(), "results page:", err)
+		return nil
+	}
+
+	seen := map[string]bool{}
+	doc.Find(ws.Selectors().Result).Each(func(_ int, a *goquery.Selection) {
+		candidate, ok := src.WebResult(base, ws.ResultLink(a.AttrOr("href", "")), strings.TrimSpace(a.Text()))
+		if !ok || seen[candidate.URL] {
+			return
+		}
+		seen[candidate.URL] = true
+		candidates = append(candidates, candidate)
+	})
+	return
+}
+
+// Bing. the results page needs a browser
+type bingSearch struct{}
+
+func (bingSearch) Name() string { return WebSearchBing }
+func (bingSearch) SearchURL(query string) string {
+	return CurrentBaseURLs().Bing + "/search?q=" + url.QueryEscape(query)
+}
+func (bingSearch) Selectors() SearchSelectors    { return CurrentSelectors().Bing }
+func (bingSearch) ResultLink(href string) string { return href }
+
+// the HTML only version of DuckDuckGo
+type duckDuckGoSearch struct{}
+
+func (duckDuckGoSearch) Name() string { return WebSearchDuckDuckGo }
+func (duckDuckGoSearch) SearchURL(query string) string {
+	return CurrentBaseURLs().DuckDuckGo + "/html/?q=" + url.QueryEscape(query)
+}
+func (duckDuckGoSearch) Selectors() SearchSelectors { return CurrentSelectors().DuckDuckGo }
+
+// results link to a redirect of DuckDuckGo with the real link in "uddg"
+// eg. //duckduckgo.com/l/?uddg=https%3A%2F%2Fhowlongtobeat.com%2Fgame%2F42818
+func (duckDuckGoSearch) ResultLink(href string) string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	if link := u.Query().Get("uddg"); link != "" {
+		return link
+	}
+	return href
+}
+
+// an instance of SearXNG, such as one run locally. only used once its address is set
+type searxngSearch struct{}
+
+func (searxngSearch) Name() string { return WebSearchSearXNG }
+func (searxngSearch) SearchURL(query string) string {
+	base := CurrentBaseURLs().SearXNG
+	if base == "" {
+		return ""
+	}
+	return base + "/search?q=" + url.QueryEscape(query)
+}
+func (searxngSearch) Selectors() SearchSelectors    { return CurrentSelectors().SearXNG }
+func (searxngSearch) ResultLink(href string) string { return href }
@@ -11,22 +11,32 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/EZRA-DVLPR/GameList/internal/dbhandler"
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
 	"github.com/EZRA-DVLPR/GameList/model"
 )
 
 var prevWidth float32
 
+// display name of each category of time
+var categoryHeaders = map[string]string{
+	scraper.CategoryMain:     "Main Story",
+	scraper.CategoryMainPlus: "Main + Sides",
+	scraper.CategoryComp:     "Completionist",
+	scraper.CategoryCoop:     "Co-Op",
+	scraper.CategoryVs:       "Vs.",
+	scraper.CategorySpeedrun: "Speedrun",
+}
+
 // makes the table and reflects changes based on values of bindings
 func createDBRender(availableThemes map[string]ColorTheme) (dbRender *widget.Table) {
-	// if db exists then get the data
-	log.Println("Checking existence of local DB")
-	if dbhandler.CheckDBExists() {
-		log.Println("DB exists. Obtaining data with stored defaults")
-		// no initial search query so use ""
-		dbData.Set(dbhandler.SortDB())
+	// the games table is created when the DB is opened, so get the data
+	log.Println("Obtaining data from local DB with stored defaults")
+	// no initial search query so use ""
+	sorted, err := store.SortDB()
+	if err != nil {
+		showError(err)
 	} else {
-		log.Println("No DB found!")
-		dbhandler.CreateDB()
+		dbData.Set(sorted)
 	}
 	data, _ := dbData.Get()
 
@@ -40,10 +50,11 @@ func createDBRender(availableThemes map[string]ColorTheme) (dbRender *widget.Tab
 	currTheme := availableThemes[st]
 
 	log.Println("Creating the table template")
+	numCols := len(dbhandler.ShownColumns())
 	// populate table with info
 	dbRender = widget.NewTableWithHeaders(
 		// table dims
-		func() (int, int) { return numRows, 4 },
+		func() (int, int) { return numRows, numCols },
 		// create empty cells with dflt bg color and empty text
 		func() fyne.CanvasObject {
 			bg := canvas.NewRectangle(hexToColor(currTheme.Background))
@@ -58,17 +69,7 @@ func createDBRender(availableThemes map[string]ColorTheme) (dbRender *widget.Tab
 
 			// if there is data in DB then display it o/w display "No Data"
 			if len(data) > 1 {
-				if id.Col == 0 {
-					// if game name is too long, then truncate and append "...". o/w display entire game name
-					if len(data[id.Row][0]) < 48 {
-						label.SetText(data[id.Row][0])
-					} else {
-						label.SetText(data[id.Row][0][:45] + "...")
-					}
-				} else {
-					// display the time data
-					label.SetText(fmt.Sprintf("%v", data[id.Row][id.Col]))
-				}
+				setCellText(label, data, id)
 			} else {
 				label.SetText("No Data")
 			}
@@ -120,6 +121,15 @@ func createDBRender(availableThemes map[string]ColorTheme) (dbRender *widget.Tab
 		dbRender.Refresh()
 	})
 
+	// change the columns of the table when different columns are chosen
+	model.AddColumnsListener(func(val string) {
+		log.Println("Shown Columns changed. Adjusting Table")
+		width := w.Content().Size().Width
+		UpdateDBData()
+		dbRender = updateTable(dbRender, width, availableThemes)
+		dbRender.Refresh()
+	})
+
 	// change contents of dbData binding when search text changes
 	model.AddSearchTextListener(func(val string) {
 		log.Println("Search Text changed. Adjusting Table")
@@ -172,7 +182,12 @@ func createDBRender(availableThemes map[string]ColorTheme) (dbRender *widget.Tab
 // sets dbData with given opts
 func UpdateDBData() {
 	model.SetSelectedRow(-1)
-	dbData.Set(dbhandler.SortDB())
+	data, err := store.SortDB()
+	if err != nil {
+		showError(err)
+		return
+	}
+	dbData.Set(data)
 }
 
 // update the contents of the given table
@@ -194,7 +209,8 @@ func updateTable(
 	currTheme := availableThemes[st]
 
 	// set dims
-	dbRender.Length = func() (int, int) { return numRows, 4 }
+	numCols := len(dbhandler.ShownColumns())
+	dbRender.Length = func() (int, int) { return numRows, numCols }
 	dbRender.UpdateCell = func(id widget.TableCellID, obj fyne.CanvasObject) {
 		// get the label from the stack
 		stack := obj.(*fyne.Container)
@@ -204,17 +220,7 @@ func updateTable(
 		// if there is data in DB then display it
 		// o/w display "No Data"
 		if len(data) != 0 {
-			if id.Col == 0 {
-				// if game name is too long, then truncate and append "...". o/w display entire game name
-				if len(data[id.Row][0]) < 48 {
-					label.SetText(data[id.Row][0])
-				} else {
-					label.SetText(data[id.Row][0][:45] + "...")
-				}
-			} else {
-				// display the time data
-				label.SetText(fmt.Sprintf("%v", data[id.Row][id.Col]))
-			}
+			setCellText(label, data, id)
 		} else {
 			label.SetText("No Data")
 		}
@@ -249,7 +255,7 @@ func headerSetup(
 	currTheme := availableThemes[st]
 
 	// name of each column header
-	headers := []string{"Game Name", "Main Story", "Main + Sides", "Completionist"}
+	cols := dbhandler.ShownColumns()
 
 	// setup for creating the headers
 	dbTable.CreateHeader = func() fyne.CanvasObject {
@@ -276,18 +282,10 @@ func headerSetup(
 			button.Show()
 			labelBG.Hide()
 			label.Hide()
-			button.SetText(headers[id.Col])
+			button.SetText(cols[id.Col].Header)
 			button.OnTapped = func() {
 				// sortCategory gets set to whichever header was clicked
-				if id.Col == 0 {
-					model.SetSortCategory("name")
-				} else if id.Col == 1 {
-					model.SetSortCategory("main")
-				} else if id.Col == 2 {
-					model.SetSortCategory("mainPlus")
-				} else {
-					model.SetSortCategory("comp")
-				}
+				model.SetSortCategory(cols[id.Col].Name)
 			}
 		} else {
 			// display row label index, from 1:rows
@@ -301,16 +299,45 @@ func headerSetup(
 	}
 
 	// set column widths
+	setColumnWidths(dbTable, width, len(cols))
+	return dbTable
+}
+
+// game name has 400, and the row headers take ~70 spacing
+// all other space is to be given to the other columns
+func setColumnWidths(dbTable *widget.Table, width float32, numCols int) {
 	dbTable.SetColumnWidth(0, 400)
+	if numCols < 2 {
+		return
+	}
+	spacing := (width - 400 - 70) / float32(numCols-1)
+	for col := 1; col < numCols; col++ {
+		dbTable.SetColumnWidth(col, spacing)
+	}
+}
 
-	// game name has 400, and the row headers take ~70 spacing
-	// all other space is to be given to the other columns
-	spacing := (width - 400 - 70) / 3
+// displays the value of the cell. long game names are cut short and other long values end with "..."
+func setCellText(label *widget.Label, data [][]string, id widget.TableCellID) {
+	// the columns may have changed before the data did
+	if id.Row >= len(data) || id.Col >= len(data[id.Row]) {
+		label.SetText("")
+		return
+	}
 
-	dbTable.SetColumnWidth(1, spacing)
-	dbTable.SetColumnWidth(2, spacing)
-	dbTable.SetColumnWidth(3, spacing)
-	return dbTable
+	if id.Col == 0 {
+		label.Truncation = fyne.TextTruncateOff
+		// if game name is too long, then truncate and append "...". o/w display entire game name
+		if len(data[id.Row][0]) < 48 {
+			label.SetText(data[id.Row][0])
+		} else {
+			label.SetText(data[id.Row][0][:45] + "...")
+		}
+		return
+	}
+
+	// display the time data or details of the game
+	label.Truncation = fyne.TextTruncateEllipsis
+	label.SetText(fmt.Sprintf("%v", data[id.Row][id.Col]))
 }
 
 // check window size every 0.25 and adjust size of table col widths if it changes
@@ -327,16 +354,8 @@ func fixTableSize(dbRender *widget.Table) {
 				// update the table widths
 				prevWidth = width
 
-				// set name col width
-				dbRender.SetColumnWidth(0, 400)
-
-				// game name has 400, and the row headers take ~70 spacing
-				// all other space is to be given to the other columns
-				spacing := (width - 400 - 70) / 3
-
-				dbRender.SetColumnWidth(1, spacing)
-				dbRender.SetColumnWidth(2, spacing)
-				dbRender.SetColumnWidth(3, spacing)
+				// set col widths
+				setColumnWidths(dbRender, width, len(dbhandler.ShownColumns()))
 
 				dbRender.Refresh()
 			}
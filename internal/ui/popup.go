@@ -1,11 +1,16 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"image/color"
 	"log"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -51,12 +56,27 @@ func singleGameNameSearchPopup() {
 				if valid {
 					// bring up progress menu
 					model.SetMaxProcesses(1)
-					PopProgressBar(0)
 
 					// search game data then add to db
-					dbhandler.SearchAddToDB(mainWidget.Text)
+					gameName := mainWidget.Text
+					runWithProgress(0, func(ctx context.Context) error {
+						candidates, err := store.SearchCandidates(ctx, gameName)
+						if err != nil {
+							return err
+						}
 
-					UpdateDBData()
+						// the user picks the game when the best match may not be the one they meant
+						chosen, confident := dbhandler.BestCandidates(candidates)
+						if !confident {
+							var ok bool
+							chosen, ok = chooseCandidates(gameName, candidates)
+							if !ok {
+								log.Println("User did not choose a game for:", gameName)
+								return context.Canceled
+							}
+						}
+						return store.AddChosen(ctx, gameName, chosen)
+					})
 
 				} else {
 					log.Println("No Game Name given for search")
@@ -69,6 +89,64 @@ func singleGameNameSearchPopup() {
 	)
 }
 
+// most candidates of a source that are shown to choose from
+const maxShownCandidates = 10
+
+// asks which of the games found on each source is the one that was meant
+// blocks until the user answers, so it must not be called from the main thread
+// ok is false if they cancelled or chose none of the games
+func chooseCandidates(gameName string, candidates map[string][]scraper.Candidate) (chosen map[string]scraper.Candidate, ok bool) {
+	noneOption := "None of these"
+
+	var list []*widget.FormItem
+	selectors := map[string]*widget.Select{}
+	byLabel := map[string]map[string]scraper.Candidate{}
+	for _, sourceName := range scraper.SourceNames() {
+		found := candidates[sourceName]
+		if len(found) == 0 {
+			continue
+		}
+
+		// number the options since two games may have the same name
+		byLabel[sourceName] = map[string]scraper.Candidate{}
+		var labels []string
+		for i, candidate := range found[:min(len(found), maxShownCandidates)] {
+			label := fmt.Sprintf("%d. %s (%.0f%% match)", i+1, candidate, candidate.Score*100)
+			labels = append(labels, label)
+			byLabel[sourceName][label] = candidate
+		}
+		labels = append(labels, noneOption)
+
+		selector := widget.NewSelect(labels, nil)
+		selector.SetSelected(labels[0])
+		selectors[sourceName] = selector
+		list = append(list, widget.NewFormItem(sourceName, selector))
+	}
+
+	answer := make(chan bool)
+	dialog.ShowForm(
+		fmt.Sprintf("Which game is %q?", gameName),
+		"Add",
+		"Cancel",
+		list,
+		func(submitted bool) {
+			answer <- submitted
+		},
+		w,
+	)
+	if !<-answer {
+		return nil, false
+	}
+
+	chosen = map[string]scraper.Candidate{}
+	for sourceName, selector := range selectors {
+		if candidate, found := byLabel[sourceName][selector.Selected]; found {
+			chosen[sourceName] = candidate
+		}
+	}
+	return chosen, len(chosen) != 0
+}
+
 func manualEntryPopup() {
 	var list []*widget.FormItem
 
@@ -136,11 +214,15 @@ func manualEntryPopup() {
 					newgame.Main = float32(mainfl)
 					newgame.MainPlus = float32(mainplusfl)
 					newgame.Comp = float32(compfl)
+					// no other ways of playing are entered by hand
+					newgame.Coop, newgame.Vs, newgame.Speedrun = -1, -1, -1
 					newgame.HLTBUrl = strings.TrimSpace(hltbURL.Text)
 					newgame.CompletionatorUrl = strings.TrimSpace(completionatorURL.Text)
 					newgame.Favorite = 0
 
-					dbhandler.AddToDB(newgame)
+					if err := store.AddToDB(newgame); err != nil {
+						showError(err)
+					}
 					UpdateDBData()
 				} else {
 					log.Println("No Game Name given for search")
@@ -153,6 +235,213 @@ func manualEntryPopup() {
 	)
 }
 
+// shows the times each source reported for the game side by side
+func sourceTimesPopup(gameName string) {
+	times, err := store.GameTimes(gameName)
+	if err != nil {
+		showError(err)
+		return
+	}
+	if len(times) == 0 {
+		dialog.ShowInformation("Source Times", "No times from any source are saved for: "+gameName, w)
+		return
+	}
+
+	// index the times by source then category for filling the grid
+	sourceNames := scraper.SourceNames()
+	values := map[string]map[string]dbhandler.GameTime{}
+	fetched := map[string]string{}
+	for _, gt := range times {
+		if values[gt.Source] == nil {
+			values[gt.Source] = map[string]dbhandler.GameTime{}
+		}
+		values[gt.Source][gt.Category] = gt
+		fetched[gt.Source] = gt.FetchedAt.Format("2006-01-02")
+	}
+
+	// one column per source, one row per category
+	grid := container.New(layout.NewGridLayout(len(sourceNames) + 1))
+	grid.Add(widget.NewLabel(""))
+	for _, sourceName := range sourceNames {
+		grid.Add(widget.NewLabelWithStyle(sourceName, fyne.TextAlignCenter, fyne.TextStyle{Bold: true}))
+	}
+	for _, category := range scraper.Categories {
+		// most games have no co-op, versus nor speedrun times, so those rows are only shown when a source has one
+		found := false
+		for _, sourceName := range sourceNames {
+			_, ok := values[sourceName][category]
+			found = found || ok
+		}
+		if !found && !slices.Contains(scraper.PlatformCategories, category) {
+			continue
+		}
+
+		grid.Add(widget.NewLabelWithStyle(categoryHeaders[category], fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		for _, sourceName := range sourceNames {
+			if gt, ok := values[sourceName][category]; ok {
+				grid.Add(widget.NewLabelWithStyle(sourceTimeText(gt), fyne.TextAlignCenter, fyne.TextStyle{}))
+			} else {
+				grid.Add(widget.NewLabelWithStyle("--", fyne.TextAlignCenter, fyne.TextStyle{}))
+			}
+		}
+	}
+	grid.Add(widget.NewLabelWithStyle("Fetched", fyne.TextAlignLeading, fyne.TextStyle{Italic: true}))
+	for _, sourceName := range sourceNames {
+		grid.Add(widget.NewLabelWithStyle(fetched[sourceName], fyne.TextAlignCenter, fyne.TextStyle{Italic: true}))
+	}
+
+	dialog.ShowCustom("Source Times for "+gameName, "Close", grid, w)
+}
+
+// form with the link to the page of the game on each source
+// the links are checked and the game is updated from them, so a game matched to the wrong page can be fixed
+func editSourcesPopup(gameName string) {
+	saved, err := store.SourceURLs(gameName)
+	if err != nil {
+		showError(err)
+		return
+	}
+
+	var list []*widget.FormItem
+	entries := map[string]*widget.Entry{}
+	for _, name := range scraper.SourceNames() {
+		entry := widget.NewEntry()
+		entry.SetText(saved[name])
+		entry.SetPlaceHolder("Link to the page of the game on " + name)
+		entries[name] = entry
+		list = append(list, widget.NewFormItem("URL for "+name, entry))
+	}
+
+	formDialog := dialog.NewForm(
+		"Sources for "+gameName,
+		"Save & Update",
+		"Cancel",
+		list,
+		func(submitted bool) {
+			if !submitted {
+				return
+			}
+			links := map[string]string{}
+			for name, entry := range entries {
+				links[name] = entry.Text
+			}
+
+			log.Println("Binding game to new source links:", gameName)
+			model.SetMaxProcesses(1)
+			runWithProgress(1, func(ctx context.Context) error {
+				return store.SetSourceURLs(ctx, gameName, links)
+			})
+		},
+		w,
+	)
+	formDialog.Resize(fyne.NewSize(600, formDialog.MinSize().Height))
+	formDialog.Show()
+}
+
+// lets the user choose the platform the game is owned on, from those the sources have times for
+// the table then shows the times on that platform. "Any" shows the times across every platform
+func myPlatformPopup(gameName string) {
+	current, err := store.MyPlatform(gameName)
+	if err != nil {
+		showError(err)
+		return
+	}
+	times, err := store.PlatformTimes(gameName)
+	if err != nil {
+		showError(err)
+		return
+	}
+
+	// a platform the user typed in before is kept as an option
+	options := []string{"Any"}
+	for _, pt := range times {
+		if !slices.Contains(options, pt.Platform) {
+			options = append(options, pt.Platform)
+		}
+	}
+	if current != "" && !slices.Contains(options, current) {
+		options = append(options, current)
+	}
+
+	// any text can be entered, so "PlayStation" can cover every PlayStation
+	selector := widget.NewSelectEntry(options)
+	selector.SetText("Any")
+	if current != "" {
+		selector.SetText(current)
+	}
+
+	// the times on each platform so the user can see what each choice shows
+	grid := container.New(layout.NewGridLayout(len(scraper.PlatformCategories) + 2))
+	grid.Add(widget.NewLabelWithStyle("Platform", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	grid.Add(widget.NewLabelWithStyle("Polled", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}))
+	for _, category := range scraper.PlatformCategories {
+		grid.Add(widget.NewLabelWithStyle(categoryHeaders[category], fyne.TextAlignCenter, fyne.TextStyle{Bold: true}))
+	}
+	for _, pt := range times {
+		grid.Add(widget.NewLabel(pt.Platform + " (" + pt.Source + ")"))
+		grid.Add(widget.NewLabelWithStyle(strconv.Itoa(pt.Polled), fyne.TextAlignCenter, fyne.TextStyle{}))
+		for _, category := range scraper.PlatformCategories {
+			text := "--"
+			if value := pt.Time(category); value > 0 {
+				text = fmt.Sprintf("%v", value)
+			}
+			grid.Add(widget.NewLabelWithStyle(text, fyne.TextAlignCenter, fyne.TextStyle{}))
+		}
+	}
+	if len(times) == 0 {
+		grid = container.New(layout.NewGridLayout(1), widget.NewLabel("No times on each platform are saved for this game"))
+	}
+
+	dialog.ShowCustomConfirm(
+		"Platform for "+gameName,
+		"Save",
+		"Cancel",
+		container.NewVBox(selector, grid),
+		func(submitted bool) {
+			if !submitted {
+				return
+			}
+			platform := strings.TrimSpace(selector.Text)
+			if platform == "Any" {
+				platform = ""
+			}
+			if err := store.SetMyPlatform(gameName, platform); err != nil {
+				showError(err)
+				return
+			}
+			UpdateDBData()
+		},
+		w,
+	)
+}
+
+// the time followed by how the source showed it, so a badly read time can be spotted
+// then the number of submissions and the spread of the times when the source reports them
+// eg. "0.75 (45 Mins)" or "35 (35 Hours)\n998 polled, 24 - 75.2"
+func sourceTimeText(gt dbhandler.GameTime) string {
+	text := fmt.Sprintf("%v", gt.Value)
+	if gt.Raw != "" {
+		text = fmt.Sprintf("%v (%s)", gt.Value, gt.Raw)
+	}
+
+	var stats []string
+	if gt.Polled > 0 {
+		stats = append(stats, fmt.Sprintf("%d polled", gt.Polled))
+	}
+	if gt.Low >= 0 && gt.High >= 0 {
+		stats = append(stats, fmt.Sprintf("%v - %v", roundHalf(gt.Low), roundHalf(gt.High)))
+	}
+	if len(stats) != 0 {
+		text += "\n" + strings.Join(stats, ", ")
+	}
+	return text
+}
+
+// rounds to the nearest half hour so the spread is easy to read
+func roundHalf(hours float32) float64 {
+	return math.Round(float64(hours)*2) / 2
+}
+
 func integrationImport(name string) {
 	var main string
 	var cookie string
@@ -200,20 +489,22 @@ func integrationImport(name string) {
 				}
 
 				if valid {
-					PopProgressBar(0)
 					log.Println("Sending all fields to integration:", name)
-					switch name {
-					case "gog":
-						integration.GetAllGamesGOG(mainWidget.Text)
-					case "psn":
-						integration.GetAllGamesPS(mainWidget.Text)
-					case "steam":
-						integration.GetAllGamesSteam(mainWidget.Text, cookieWidget.Text)
-					case "epic":
-						integration.GetAllGamesEpicString(mainWidget.Text)
-					default:
-						log.Println("Integration not found:", name)
-					}
+					runWithProgress(0, func(ctx context.Context) error {
+						switch name {
+						case "gog":
+							return integration.GetAllGamesGOG(ctx, store, mainWidget.Text)
+						case "psn":
+							return integration.GetAllGamesPS(ctx, store, mainWidget.Text)
+						case "steam":
+							return integration.GetAllGamesSteam(ctx, store, mainWidget.Text, cookieWidget.Text)
+						case "epic":
+							return integration.GetAllGamesEpicString(ctx, store, mainWidget.Text)
+						default:
+							log.Println("Integration not found:", name)
+							return nil
+						}
+					})
 				} else {
 					log.Println("Please ensure all fields have proper integration input for:", name)
 				}
@@ -241,6 +532,18 @@ func settingsPopup(availableThemes map[string]ColorTheme) {
 				layout.NewVBoxLayout(),
 				searchSourceRadioWidget(),
 				widget.NewSeparator(),
+				mergeStrategySelector(),
+				widget.NewSeparator(),
+				concurrencySelector(),
+				widget.NewSeparator(),
+				webSearchSelector(),
+				widget.NewSeparator(),
+				cacheSettings(),
+				widget.NewSeparator(),
+				aliasesButton(),
+				widget.NewSeparator(),
+				columnsSelector(),
+				widget.NewSeparator(),
 				themeSelector(availableThemes),
 				widget.NewSeparator(),
 				textSlider(availableThemes),
@@ -264,12 +567,9 @@ func searchSourceRadioWidget() *fyne.Container {
 		fyne.TextAlignCenter,
 		fyne.TextStyle{Bold: true},
 	)
+	// every registered source can be picked on its own, or all of them together
 	radio := widget.NewRadioGroup(
-		[]string{
-			"All",
-			"HLTB",
-			"Completionator",
-		},
+		append([]string{scraper.AllSources}, scraper.SourceNames()...),
 		func(value string) {
 			model.SetSearchSource(value)
 			log.Println("Search Source changed to:", value)
@@ -287,6 +587,410 @@ func searchSourceRadioWidget() *fyne.Container {
 	)
 }
 
+// selector for how the times of several sources are combined
+func mergeStrategySelector() *fyne.Container {
+	label := widget.NewLabelWithStyle(
+		"Combining Times From All Sources",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	// map the display name of each strategy to the value that is saved
+	strategies := dbhandler.MergeStrategies()
+	names := make([]string, len(strategies))
+	byName := map[string]string{}
+	for i, strategy := range strategies {
+		names[i] = mergeStrategyName(strategy)
+		byName[names[i]] = strategy
+	}
+
+	selector := widget.NewSelect(names, nil)
+
+	// set default to merge strategy saved
+	ms, _ := model.GetMergeStrategy()
+	selector.SetSelected(mergeStrategyName(ms))
+
+	// only listen for changes after the default is set so opening the settings doesnt recompute
+	selector.OnChanged = func(name string) {
+		strategy := byName[name]
+		model.SetMergeStrategy(strategy)
+		log.Println("Merge Strategy changed to:", strategy)
+
+		// saved source times are merged again so no game has to be searched again
+		if err := store.RecomputeMergedTimes(); err != nil {
+			showError(err)
+		}
+		UpdateDBData()
+	}
+
+	return container.New(
+		layout.NewVBoxLayout(),
+		label,
+		selector,
+	)
+}
+
+// selectors for how many games are looked up at once and how many requests each site gets at once
+func concurrencySelector() *fyne.Container {
+	label := widget.NewLabelWithStyle(
+		"Lookups At Once",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+	options := []string{"1", "2", "4", "8"}
+
+	gamesSelector := widget.NewSelect(options, func(value string) {
+		n, _ := strconv.Atoi(value)
+		model.SetConcurrency(n)
+		log.Println("Concurrency changed to:", n)
+	})
+	cc, _ := model.GetConcurrency()
+	gamesSelector.SetSelected(strconv.Itoa(cc))
+
+	hostSelector := widget.NewSelect(options, func(value string) {
+		n, _ := strconv.Atoi(value)
+		model.SetHostRequests(n)
+		scraper.SetHostLimit(n, scraper.DefaultHostDelay)
+		log.Println("Requests per site changed to:", n)
+	})
+	hr, _ := model.GetHostRequests()
+	hostSelector.SetSelected(strconv.Itoa(hr))
+
+	return container.New(
+		layout.NewVBoxLayout(),
+		label,
+		widget.NewForm(
+			widget.NewFormItem("Games", gamesSelector),
+			widget.NewFormItem("Requests per Site", hostSelector),
+			widget.NewFormItem("User Agent", userAgentEntry()),
+		),
+	)
+}
+
+// the user agent sent to the sites. cleared, the one of the app is used again
+func userAgentEntry() *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(scraper.DefaultUserAgent)
+	ua, _ := model.GetUserAgent()
+	entry.SetText(ua)
+
+	entry.OnChanged = func(value string) {
+		model.SetUserAgent(value)
+		scraper.SetUserAgent(value)
+	}
+	return entry
+}
+
+// checks for which web searches are tried when the search of a site fails, and the address of a SearXNG instance
+func webSearchSelector() *fyne.Container {
+	label := widget.NewLabelWithStyle(
+		"Web Searches When a Site Search Fails",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	checks := widget.NewCheckGroup(scraper.WebSearchNames(), nil)
+	checks.Horizontal = true
+
+	// set default to the web searches saved
+	checks.SetSelected(scraper.CurrentWebSearches())
+
+	// only listen for changes after the default is set
+	checks.OnChanged = func(selected []string) {
+		list := strings.Join(selected, ",")
+		model.SetWebSearches(list)
+		scraper.SetWebSearches(selected)
+		log.Println("Web Searches changed to:", list)
+	}
+
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("http://localhost:8888")
+	sx, _ := model.GetSearXNGURL()
+	entry.SetText(sx)
+	entry.OnChanged = func(value string) {
+		model.SetSearXNGURL(value)
+		setSearXNGURL(value)
+	}
+
+	return container.New(
+		layout.NewVBoxLayout(),
+		label,
+		checks,
+		widget.NewForm(
+			widget.NewFormItem("SearXNG Address", entry),
+		),
+	)
+}
+
+// points the SearXNG web search at the instance. empty means it is not used
+func setSearXNGURL(link string) {
+	urls := scraper.CurrentBaseURLs()
+	urls.SearXNG = link
+	scraper.SetBaseURLs(urls)
+}
+
+// splits a comma separated list saved in the preferences. an empty list has no items
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
+
+// checks for which columns are shown in the table. the game name is always shown
+func columnsSelector() *fyne.Container {
+	label := widget.NewLabelWithStyle(
+		"Shown Columns",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	// map the header of each column to the column
+	var headers []string
+	byHeader := map[string]dbhandler.Column{}
+	for _, col := range dbhandler.Columns[1:] {
+		headers = append(headers, col.Header)
+		byHeader[col.Header] = col
+	}
+
+	checks := widget.NewCheckGroup(headers, nil)
+	checks.Horizontal = true
+
+	// set default to the columns saved
+	var shown []string
+	for _, col := range dbhandler.ShownColumns()[1:] {
+		shown = append(shown, col.Header)
+	}
+	checks.SetSelected(shown)
+
+	// only listen for changes after the default is set so opening the settings doesnt redraw the table
+	checks.OnChanged = func(selected []string) {
+		cols := []dbhandler.Column{dbhandler.Columns[0]}
+		for _, header := range selected {
+			cols = append(cols, byHeader[header])
+		}
+		list := dbhandler.JoinColumns(cols)
+		model.SetColumns(list)
+		log.Println("Shown Columns changed to:", list)
+	}
+
+	return container.New(
+		layout.NewVBoxLayout(),
+		label,
+		checks,
+	)
+}
+
+// offline mode and clearing of the pages cached by the scraper
+func cacheSettings() *fyne.Container {
+	label := widget.NewLabelWithStyle(
+		"Page Cache",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	offlineCheck := widget.NewCheck("Offline Mode (only use cached pages)", func(value bool) {
+		model.SetOffline(value)
+		scraper.SetOffline(value)
+		log.Println("Offline mode changed to:", value)
+	})
+	off, _ := model.GetOffline()
+	offlineCheck.SetChecked(off)
+
+	clearCache := widget.NewButton("Clear Cache", func() {
+		dialog.ShowConfirm(
+			"Clear Cached Pages",
+			"Every page will be downloaded again the next time it is needed",
+			func(submitted bool) {
+				if submitted {
+					log.Println("Clearing page cache")
+					if err := scraper.ClearCache(); err != nil {
+						showError(err)
+					}
+				}
+			},
+			w2,
+		)
+	})
+
+	return container.New(
+		layout.NewVBoxLayout(),
+		label,
+		offlineCheck,
+		clearCache,
+	)
+}
+
+// opens the list of aliases for game names that the sites never find
+func aliasesButton() *fyne.Container {
+	label := widget.NewLabelWithStyle(
+		"Game Name Aliases",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+	manage := widget.NewButton("Manage Aliases", func() {
+		aliasesPopup(w2)
+	})
+	return container.New(
+		layout.NewVBoxLayout(),
+		label,
+		manage,
+	)
+}
+
+// list of every alias with buttons to add and delete them
+func aliasesPopup(parent fyne.Window) {
+	aliases, err := store.Aliases()
+	if err != nil {
+		showError(err)
+		return
+	}
+
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(aliases) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(aliasDescription(aliases[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		selected = id
+	}
+
+	// reload the list after an alias is added or deleted
+	reload := func() {
+		aliases, err = store.Aliases()
+		if err != nil {
+			showError(err)
+		}
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+	}
+
+	addButton := widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), func() {
+		aliasEntryPopup(parent, reload)
+	})
+	deleteButton := widget.NewButtonWithIcon("Delete", theme.ContentRemoveIcon(), func() {
+		if selected < 0 {
+			return
+		}
+		if err := store.DeleteAlias(aliases[selected].Alias); err != nil {
+			showError(err)
+		}
+		reload()
+	})
+
+	content := container.NewBorder(
+		widget.NewLabel("Names here are searched as their alias, or fetched from their links"),
+		container.NewHBox(addButton, deleteButton),
+		nil, nil,
+		list,
+	)
+	aliasDialog := dialog.NewCustom("Game Name Aliases", "Close", content, parent)
+	aliasDialog.Resize(fyne.NewSize(600, 400))
+	aliasDialog.Show()
+}
+
+// form for a new alias. onSaved is called once it is saved
+func aliasEntryPopup(parent fyne.Window, onSaved func()) {
+	var list []*widget.FormItem
+
+	aliasWidget := widget.NewEntry()
+	aliasWidget.SetPlaceHolder("Name from the store")
+	list = append(list, widget.NewFormItem("Game Name", aliasWidget))
+
+	searchWidget := widget.NewEntry()
+	searchWidget.SetPlaceHolder("Name to search for instead")
+	list = append(list, widget.NewFormItem("Search As", searchWidget))
+
+	// a link for a source is fetched directly instead of searching that source
+	urlWidgets := map[string]*widget.Entry{}
+	for _, sourceName := range scraper.SourceNames() {
+		urlWidget := widget.NewEntry()
+		urlWidget.SetPlaceHolder("Link to the game on " + sourceName)
+		urlWidgets[sourceName] = urlWidget
+		list = append(list, widget.NewFormItem(sourceName+" Link", urlWidget))
+	}
+
+	dialog.ShowForm(
+		"Add Alias",
+		"Save",
+		"Cancel",
+		list,
+		func(submitted bool) {
+			if !submitted {
+				log.Println("User Cancelled adding an alias")
+				return
+			}
+
+			alias := dbhandler.Alias{
+				Alias:      aliasWidget.Text,
+				SearchName: searchWidget.Text,
+				URLs:       map[string]string{},
+			}
+			for sourceName, urlWidget := range urlWidgets {
+				alias.URLs[sourceName] = urlWidget.Text
+			}
+
+			// an alias with nothing to use instead of the name does nothing
+			hasURL := false
+			for _, url := range alias.URLs {
+				hasURL = hasURL || strings.TrimSpace(url) != ""
+			}
+			if strings.TrimSpace(alias.Alias) == "" || (strings.TrimSpace(alias.SearchName) == "" && !hasURL) {
+				log.Println("Alias needs a game name and a search name or link")
+				return
+			}
+
+			if err := store.SaveAlias(alias); err != nil {
+				showError(err)
+				return
+			}
+			onSaved()
+		},
+		parent,
+	)
+}
+
+// one line description of the alias. eg. "FF7 -> Final Fantasy VII (HLTB link)"
+func aliasDescription(alias dbhandler.Alias) string {
+	text := alias.Alias
+	if alias.SearchName != "" {
+		text += " -> " + alias.SearchName
+	}
+	var linked []string
+	for _, sourceName := range scraper.SourceNames() {
+		if alias.URLs[sourceName] != "" {
+			linked = append(linked, sourceName)
+		}
+	}
+	if len(linked) != 0 {
+		text += fmt.Sprintf(" (%s link)", strings.Join(linked, ", "))
+	}
+	return text
+}
+
+// name of the merge strategy that is shown to the user
+func mergeStrategyName(strategy string) string {
+	if sourceName, ok := dbhandler.PreferredSource(strategy); ok {
+		return fmt.Sprintf("Prefer %s, then Highest", sourceName)
+	}
+	switch strategy {
+	case dbhandler.MergeMin:
+		return "Lowest"
+	case dbhandler.MergeMean:
+		return "Average"
+	case dbhandler.MergeMedian:
+		return "Median"
+	case dbhandler.MergeWeighted:
+		return "Weighted by Submissions"
+	default:
+		return "Highest"
+	}
+}
+
 // selector for the theme of the application
 func themeSelector(availableThemes map[string]ColorTheme) *fyne.Container {
 	st, _ := model.GetSelectedTheme()
@@ -401,11 +1105,9 @@ func updateAllButton() *fyne.Container {
 					dbdata, _ := dbData.Get()
 					if len(dbdata) != 0 {
 						model.SetMaxProcesses(len(dbdata))
-						PopProgressBar(1)
 
 						log.Println("Updating Entire DB")
-						dbhandler.UpdateEntireDB()
-						UpdateDBData()
+						runWithProgress(1, store.UpdateEntireDB)
 					}
 				}
 			},
@@ -433,7 +1135,9 @@ func deleteAllButton() *fyne.Container {
 			func(submitted bool) {
 				if submitted {
 					log.Println("Deleting data in DB")
-					dbhandler.DeleteAllDBData()
+					if err := store.DeleteAllDBData(); err != nil {
+						showError(err)
+					}
 					UpdateDBData()
 					w2.Close()
 				}
@@ -466,7 +1170,28 @@ func fixedHeightRect(color color.Color) *canvas.Rectangle {
 	return rect
 }
 
-func PopProgressBar(option int) {
+// runs the work in the background while the progress bar is shown so it can be cancelled
+// once the work is done the table is refreshed and any error is shown
+func runWithProgress(option int, work func(ctx context.Context) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	finish := PopProgressBar(option, cancel)
+
+	go func() {
+		defer cancel()
+		err := work(ctx)
+		finish(err)
+
+		// the user already knows that they cancelled it
+		if err != nil && !errors.Is(err, context.Canceled) {
+			showError(err)
+		}
+		UpdateDBData()
+	}()
+}
+
+// shows the progress of the current processes with a button to cancel them
+// the returned func must be called with the result once the processes stop
+func PopProgressBar(option int, cancel context.CancelFunc) (finish func(err error)) {
 	// reset progress for bar and display it
 	model.ResetProgress()
 	progBar := widget.NewProgressBarWithData(model.GlobalModel.Progress)
@@ -494,7 +1219,7 @@ func PopProgressBar(option int) {
 			textWidget.SetText("Overwriting the old database...")
 		}
 	}
-	model.AddMaxProcessesListener(MaxProcListenerFunc)
+	MaxProcListener = model.AddMaxProcessesListener(MaxProcListenerFunc)
 
 	// create generic variable of the dialog
 	var customDialog dialog.Dialog
@@ -506,9 +1231,29 @@ func PopProgressBar(option int) {
 	})
 	actionButton.Disable()
 
+	// stops the processes after the one currently running
+	cancelButton := widget.NewButton("Cancel", func() {
+		log.Println("User cancelled the running processes")
+		cancel()
+		textWidget.SetText("Cancelling...")
+	})
+
 	// listener that increments progress bar %
 	var ProgListener binding.DataListener
 
+	// allow button to be pressed, change text, and remove listeners
+	var once sync.Once
+	done := func(text string) {
+		once.Do(func() {
+			cancelButton.Disable()
+			actionButton.Enable()
+			actionButton.SetText(text)
+			actionButton.Refresh()
+			model.RemoveProgressListener(ProgListener)
+			model.RemoveMaxProcessesListener(MaxProcListener)
+		})
+	}
+
 	// function for the listener to use
 	ProgListenerFunc := func(progUpdate float64) {
 		// compare update value and max # processes
@@ -517,26 +1262,33 @@ func PopProgressBar(option int) {
 			log.Fatal("Error getting max processes for display", err)
 		}
 		if progUpdate == float64(procmax) {
-			// allow button to be pressed, change text, and remove listeners
-			actionButton.Enable()
-			actionButton.SetText("Processing Completed!")
-			actionButton.Refresh()
-			model.RemoveProgressListener(ProgListener)
-			model.RemoveMaxProcessesListener(MaxProcListener)
+			done("Processing Completed!")
 		}
 	}
 
 	// attach progress listener
-	model.AddProgressListener(ProgListenerFunc)
+	ProgListener = model.AddProgressListener(ProgListenerFunc)
 
 	// container with the things to be displayed in the dialog
 	content := container.NewVBox(
 		textWidget,
 		progBar,
-		actionButton,
+		container.NewGridWithColumns(2, cancelButton, actionButton),
 	)
 
 	// create and show the custom dialog
 	customDialog = dialog.NewCustomWithoutButtons("Processing Window", content, w)
 	customDialog.Show()
+
+	// processes may stop early from cancelling or an error, so the progress bar may not be full
+	return func(err error) {
+		if errors.Is(err, context.Canceled) {
+			progress, _ := model.GetProgress()
+			procmax, _ := model.GetMaxProcesses()
+			textWidget.SetText(fmt.Sprintf("Cancelled after %d of %d game(s)", int(progress), procmax))
+			done("Close")
+			return
+		}
+		done("Processing Completed!")
+	}
 }
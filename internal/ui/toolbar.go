@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	_ "embed"
 	"log"
 	"math/rand"
@@ -16,7 +17,6 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
-	"github.com/EZRA-DVLPR/GameList/internal/dbhandler"
 	"github.com/EZRA-DVLPR/GameList/model"
 )
 
@@ -43,6 +43,12 @@ func createMainWindowToolbar(
 		layout.NewSpacer(),
 		createFaveButton(),
 		layout.NewSpacer(),
+		createSourceTimesButton(),
+		layout.NewSpacer(),
+		createEditSourcesButton(),
+		layout.NewSpacer(),
+		createPlatformButton(),
+		layout.NewSpacer(),
 		createExportButton(),
 		layout.NewSpacer(),
 		createHelpButton(),
@@ -99,7 +105,9 @@ func createExportButton() (exportButton *widget.Button) {
 				os.Remove(uri.URI().Path())
 
 				//export
-				dbhandler.Export(1, uri.URI().Path())
+				if err := store.Export(1, uri.URI().Path()); err != nil {
+					showError(err)
+				}
 			}, w)
 		}),
 		fyne.NewMenuItem("Export to SQL", func() {
@@ -114,7 +122,9 @@ func createExportButton() (exportButton *widget.Button) {
 				}
 				defer uri.Close() // close uri when dialog closes
 				os.Remove(uri.URI().Path())
-				dbhandler.Export(2, uri.URI().Path())
+				if err := store.Export(2, uri.URI().Path()); err != nil {
+					showError(err)
+				}
 			}, w)
 		}),
 		fyne.NewMenuItem("Export to MD", func() {
@@ -129,7 +139,9 @@ func createExportButton() (exportButton *widget.Button) {
 				}
 				defer uri.Close() // close uri when dialog closes
 				os.Remove(uri.URI().Path())
-				dbhandler.Export(3, uri.URI().Path())
+				if err := store.Export(3, uri.URI().Path()); err != nil {
+					showError(err)
+				}
 			}, w)
 		}),
 	}
@@ -177,9 +189,10 @@ func createAddButton() (addButton *widget.Button) {
 					return
 				}
 				defer uri.Close() // close uri when dialog closes
-				PopProgressBar(0)
-				dbhandler.Import(1, uri.URI().Path())
-				UpdateDBData()
+				path := uri.URI().Path()
+				runWithProgress(0, func(ctx context.Context) error {
+					return store.Import(ctx, 1, path)
+				})
 			}, w)
 			// set file extension to only allow csv files
 			fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
@@ -197,9 +210,10 @@ func createAddButton() (addButton *widget.Button) {
 					return
 				}
 				defer uri.Close()
-				PopProgressBar(2)
-				dbhandler.Import(2, uri.URI().Path())
-				UpdateDBData()
+				path := uri.URI().Path()
+				runWithProgress(2, func(ctx context.Context) error {
+					return store.Import(ctx, 2, path)
+				})
 			}, w)
 			fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".sql"}))
 			fileDialog.Show()
@@ -216,9 +230,10 @@ func createAddButton() (addButton *widget.Button) {
 					return
 				}
 				defer uri.Close()
-				PopProgressBar(0)
-				dbhandler.Import(3, uri.URI().Path())
-				UpdateDBData()
+				path := uri.URI().Path()
+				runWithProgress(0, func(ctx context.Context) error {
+					return store.Import(ctx, 3, path)
+				})
 			}, w)
 			fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".txt"}))
 			fileDialog.Show()
@@ -260,7 +275,9 @@ func createRemoveButton() (removeButton *widget.Button) {
 			// get the game name and send query for deletion
 			dbdata, _ := dbData.Get()
 			log.Println("Removing Game:", dbdata[selrow][0])
-			dbhandler.DeleteFromDB(dbdata[selrow][0])
+			if err := store.DeleteFromDB(dbdata[selrow][0]); err != nil {
+				showError(err)
+			}
 
 			UpdateDBData()
 		}
@@ -320,7 +337,9 @@ func createFaveButton() (faveButton *widget.Button) {
 		if selrow >= 0 {
 			// get the game name and send query for toggling favorite
 			dbdata, _ := dbData.Get()
-			dbhandler.ToggleFavorite(dbdata[selrow][0])
+			if err := store.ToggleFavorite(dbdata[selrow][0]); err != nil {
+				showError(err)
+			}
 
 			UpdateDBData()
 		}
@@ -329,6 +348,45 @@ func createFaveButton() (faveButton *widget.Button) {
 	return faveButton
 }
 
+// show the times from each source for the game defined by selectedRow
+func createSourceTimesButton() (sourceTimesButton *widget.Button) {
+	sourceTimesButton = widget.NewButtonWithIcon("Sources", theme.InfoIcon(), func() {
+		selrow, _ := model.GetSelectedRow()
+		if selrow >= 0 {
+			dbdata, _ := dbData.Get()
+			sourceTimesPopup(dbdata[selrow][0])
+		}
+	})
+
+	return sourceTimesButton
+}
+
+// change the pages of each source the game defined by selectedRow is bound to
+func createEditSourcesButton() (editSourcesButton *widget.Button) {
+	editSourcesButton = widget.NewButtonWithIcon("Edit Sources", theme.DocumentCreateIcon(), func() {
+		selrow, _ := model.GetSelectedRow()
+		if selrow >= 0 {
+			dbdata, _ := dbData.Get()
+			editSourcesPopup(dbdata[selrow][0])
+		}
+	})
+
+	return editSourcesButton
+}
+
+// choose the platform the game defined by selectedRow is owned on
+func createPlatformButton() (platformButton *widget.Button) {
+	platformButton = widget.NewButtonWithIcon("Platform", theme.ComputerIcon(), func() {
+		selrow, _ := model.GetSelectedRow()
+		if selrow >= 0 {
+			dbdata, _ := dbData.Get()
+			myPlatformPopup(dbdata[selrow][0])
+		}
+	})
+
+	return platformButton
+}
+
 // update the selected game defined by selectedRow
 func createUpdateButton() (updateButton *widget.Button) {
 	updateButton = widget.NewButtonWithIcon("Update", theme.MediaReplayIcon(), func() {
@@ -338,12 +396,12 @@ func createUpdateButton() (updateButton *widget.Button) {
 
 			// bring up progress menu
 			model.SetMaxProcesses(1)
-			PopProgressBar(1)
 
 			dbdata, _ := dbData.Get()
-			dbhandler.UpdateGame(dbdata[selrow][0])
-
-			UpdateDBData()
+			gameName := dbdata[selrow][0]
+			runWithProgress(1, func(ctx context.Context) error {
+				return store.UpdateGame(ctx, gameName)
+			})
 		}
 	})
 
@@ -3,24 +3,33 @@ package ui
 import (
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
 
+	"github.com/EZRA-DVLPR/GameList/internal/dbhandler"
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
 	"github.com/EZRA-DVLPR/GameList/model"
 )
 
-// global vars holding the app, main window, and dbData
+// global vars holding the app, main window, dbData, and the opened games DB
 var (
 	a      fyne.App
 	w      fyne.Window
 	dbData *String2DBinding
+	store  *dbhandler.Store
 )
 
 func StartGUI() {
@@ -52,6 +61,26 @@ func StartGUI() {
 	}
 	defer logFile.Close()
 
+	// open the local DB that lives alongside the logs and themes
+	store, err = dbhandler.Open(filepath.Join(execPath, "games.db"))
+	if err != nil {
+		log.Fatal("Error opening local DB:", err)
+	}
+	defer store.Close()
+
+	// the browser used for searching is only started once a search needs it
+	defer scraper.Shutdown()
+
+	// pages fetched by the scraper are cached alongside the DB
+	if err := scraper.SetCache(filepath.Join(execPath, "cache"), scraper.DefaultCacheTTL); err != nil {
+		log.Println("Error setting up page cache. Continuing without it:", err)
+	}
+
+	// the selectors used to read the sites live alongside the DB so they can be fixed without a new version
+	if err := scraper.LoadSelectors(filepath.Join(execPath, "selectors.yaml")); err != nil {
+		log.Println("Error loading selectors. Continuing with the default selectors:", err)
+	}
+
 	a = app.NewWithID(".EZRA-DVLPR.GameList")
 	w = a.NewWindow(fmt.Sprintf("Main window - GameList v%v", version))
 
@@ -71,10 +100,47 @@ func StartGUI() {
 	storedSortOrder := prefs.BoolWithFallback("sort_order", true)
 	model.SetSortOrder(storedSortOrder)
 
+	// load shown columns from preferences storage. default to the game name and its times
+	storedColumns := prefs.StringWithFallback("columns", strings.Join(dbhandler.DefaultColumns, ","))
+	model.SetColumns(storedColumns)
+
 	// load search sort from preferences storage. default to "All"
-	storedSearchSort := prefs.StringWithFallback("search_source", "All")
+	storedSearchSort := prefs.StringWithFallback("search_source", scraper.AllSources)
 	model.SetSearchSource(storedSearchSort)
 
+	// load merge strategy from preferences storage. default to the highest time
+	storedMergeStrategy := prefs.StringWithFallback("merge_strategy", dbhandler.MergeMax)
+	model.SetMergeStrategy(storedMergeStrategy)
+
+	// load number of games looked up at once from preferences storage. default to 4
+	storedConcurrency := prefs.IntWithFallback("concurrency", 4)
+	model.SetConcurrency(storedConcurrency)
+
+	// load number of requests made to a site at once from preferences storage
+	storedHostRequests := prefs.IntWithFallback("host_requests", scraper.DefaultHostParallelism)
+	model.SetHostRequests(storedHostRequests)
+	scraper.SetHostLimit(storedHostRequests, scraper.DefaultHostDelay)
+
+	// load offline mode from preferences storage. default to false (sites are contacted)
+	storedOffline := prefs.BoolWithFallback("offline", false)
+	model.SetOffline(storedOffline)
+	scraper.SetOffline(storedOffline)
+
+	// load user agent sent to the sites from preferences storage. default to the one of the app
+	storedUserAgent := prefs.StringWithFallback("user_agent", scraper.DefaultUserAgent)
+	model.SetUserAgent(storedUserAgent)
+	scraper.SetUserAgent(storedUserAgent)
+
+	// load web searches tried when the search of a site fails from preferences storage. default to Bing
+	storedWebSearches := prefs.StringWithFallback("web_searches", strings.Join(scraper.DefaultWebSearches, ","))
+	model.SetWebSearches(storedWebSearches)
+	scraper.SetWebSearches(splitList(storedWebSearches))
+
+	// load address of the SearXNG instance from preferences storage. default to none
+	storedSearXNGURL := prefs.StringWithFallback("searxng_url", "")
+	model.SetSearXNGURL(storedSearXNGURL)
+	setSearXNGURL(storedSearXNGURL)
+
 	// default window size accommodates changing of "ASC"/"DESC" without changing size of window (1140, 400) (W,H)
 	storedWWidth := prefs.FloatWithFallback("w_width", 1080)
 	wWidth.Set(storedWWidth)
@@ -112,10 +178,11 @@ func StartGUI() {
 
 	// display the contents of the app
 	content := container.NewBorder(
-		// top is toolbar + searchbar
+		// top is toolbar + searchbar + warning for broken sources
 		container.NewVBox(
 			createMainWindowToolbar(availableThemes),
 			createSearchBar(),
+			createBrokenSourcesBanner(),
 		),
 		// dont render anything else in space besides DB
 		nil, nil, nil,
@@ -147,10 +214,38 @@ func StartGUI() {
 		wH, _ = wHeight.Get()
 		prefs.SetFloat("w_height", wH)
 
+		// save shown columns
+		cols, _ := model.GetColumns()
+		prefs.SetString("columns", cols)
+
 		// save search source
 		ss, _ := model.GetSearchSource()
 		prefs.SetString("search_source", ss)
 
+		// save merge strategy
+		ms, _ := model.GetMergeStrategy()
+		prefs.SetString("merge_strategy", ms)
+
+		// save number of games looked up at once and requests made to a site at once
+		cc, _ := model.GetConcurrency()
+		prefs.SetInt("concurrency", cc)
+		hr, _ := model.GetHostRequests()
+		prefs.SetInt("host_requests", hr)
+
+		// save offline mode
+		off, _ := model.GetOffline()
+		prefs.SetBool("offline", off)
+
+		// save user agent
+		ua, _ := model.GetUserAgent()
+		prefs.SetString("user_agent", ua)
+
+		// save web searches and the address of the SearXNG instance
+		ws, _ := model.GetWebSearches()
+		prefs.SetString("web_searches", ws)
+		sx, _ := model.GetSearXNGURL()
+		prefs.SetString("searxng_url", sx)
+
 		// save text size
 		ts, _ := model.GetTextSize()
 		prefs.SetFloat("text_size", ts)
@@ -162,9 +257,17 @@ func StartGUI() {
 		log.Println("Preferences saved:")
 		log.Println("Sort Category:", st)
 		log.Println("Sort Order:", so)
+		log.Println("Shown Columns:", cols)
 		log.Println("Sort Window Width:", wW)
 		log.Println("Sort Window Height:", wH)
 		log.Println("Search Source:", ss)
+		log.Println("Merge Strategy:", ms)
+		log.Println("Concurrency:", cc)
+		log.Println("Host Requests:", hr)
+		log.Println("Offline:", off)
+		log.Println("User Agent:", ua)
+		log.Println("Web Searches:", ws)
+		log.Println("SearXNG URL:", sx)
 		log.Println("Text Size:", ts)
 		log.Println("Selected Theme:", sth)
 		log.Println("App closed!")
@@ -174,6 +277,65 @@ func StartGUI() {
 	a.Run()
 }
 
+// logs the error and shows it to the user without closing the app
+func showError(err error) {
+	log.Println("Error:", err)
+	dialog.ShowError(err, w)
+}
+
+// warns that the layout of a site changed so no game can be read from it until its selectors are updated
+// hidden while every site works
+func createBrokenSourcesBanner() *fyne.Container {
+	label := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	label.Wrapping = fyne.TextWrapWord
+
+	// the listener is called from the goroutine of the search, so the errors are guarded
+	var mu sync.Mutex
+	var current map[string]error
+
+	details := widget.NewButtonWithIcon("Details", theme.InfoIcon(), func() {
+		mu.Lock()
+		var lines []string
+		for _, err := range current {
+			lines = append(lines, err.Error())
+		}
+		mu.Unlock()
+		slices.Sort(lines)
+		dialog.ShowInformation(
+			"Source Broken",
+			strings.Join(lines, "\n")+"\n\nUpdate selectors.yaml next to the app, or update the app, to read from it again",
+			w,
+		)
+	})
+	dismiss := widget.NewButtonWithIcon("", theme.CancelIcon(), nil)
+
+	banner := container.NewBorder(
+		nil, nil,
+		widget.NewIcon(theme.WarningIcon()),
+		container.NewHBox(details, dismiss),
+		label,
+	)
+	banner.Hide()
+	dismiss.OnTapped = banner.Hide
+
+	scraper.AddBrokenSourcesListener(func(broken map[string]error) {
+		mu.Lock()
+		current = broken
+		mu.Unlock()
+
+		if len(broken) == 0 {
+			log.Println("Every source is working again. Hiding banner")
+			banner.Hide()
+			return
+		}
+		names := slices.Sorted(maps.Keys(broken))
+		log.Println("Sources broken:", names)
+		label.SetText(strings.Join(names, ", ") + " changed its layout. No games can be read from it until its selectors are updated")
+		banner.Show()
+	})
+	return banner
+}
+
 // creates logfile based on: Version # and current time
 func setLogFile(version string) (*os.File, error) {
 	timestamp := time.Now().Format("2006-01-02_15-04-05")
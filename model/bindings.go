@@ -2,6 +2,7 @@ package model
 
 import (
 	"log"
+	"sync"
 
 	"fyne.io/fyne/v2/data/binding"
 )
@@ -10,8 +11,16 @@ import (
 type AppModel struct {
 	SelectedTheme binding.String
 	SearchSource  binding.String
+	MergeStrategy binding.String
+	Concurrency   binding.Int
+	HostRequests  binding.Int
+	Offline       binding.Bool
+	UserAgent     binding.String
+	WebSearches   binding.String
+	SearXNGURL    binding.String
 	SortCategory  binding.String
 	SortOrder     binding.Bool
+	Columns       binding.String
 	TextSize      binding.Float
 	SearchText    binding.String
 	SelectedRow   binding.Int
@@ -23,8 +32,16 @@ type AppModel struct {
 var GlobalModel = &AppModel{
 	SelectedTheme: binding.NewString(),
 	SearchSource:  binding.NewString(),
+	MergeStrategy: binding.NewString(),
+	Concurrency:   binding.NewInt(),
+	HostRequests:  binding.NewInt(),
+	Offline:       binding.NewBool(),
+	UserAgent:     binding.NewString(),
+	WebSearches:   binding.NewString(),
+	SearXNGURL:    binding.NewString(),
 	SortCategory:  binding.NewString(),
 	SortOrder:     binding.NewBool(),
+	Columns:       binding.NewString(),
 	TextSize:      binding.NewFloat(),
 	SearchText:    binding.NewString(),
 	SelectedRow:   binding.NewInt(),
@@ -38,6 +55,8 @@ func init() {
 	GlobalModel.SelectedRow.Set(1)
 	GlobalModel.MaxProcesses.Set(1)
 	GlobalModel.Progress.Set(0)
+	GlobalModel.Concurrency.Set(1)
+	GlobalModel.HostRequests.Set(1)
 }
 
 // INFO: All below functions are just for convenience on managing the bindings
@@ -67,6 +86,62 @@ func SetSearchSource(val string) error {
 	return GlobalModel.SearchSource.Set(val)
 }
 
+func GetMergeStrategy() (string, error) {
+	return GlobalModel.MergeStrategy.Get()
+}
+
+func SetMergeStrategy(val string) error {
+	return GlobalModel.MergeStrategy.Set(val)
+}
+
+func GetConcurrency() (int, error) {
+	return GlobalModel.Concurrency.Get()
+}
+
+func SetConcurrency(val int) error {
+	return GlobalModel.Concurrency.Set(val)
+}
+
+func GetHostRequests() (int, error) {
+	return GlobalModel.HostRequests.Get()
+}
+
+func SetHostRequests(val int) error {
+	return GlobalModel.HostRequests.Set(val)
+}
+
+func GetOffline() (bool, error) {
+	return GlobalModel.Offline.Get()
+}
+
+func SetOffline(val bool) error {
+	return GlobalModel.Offline.Set(val)
+}
+
+func GetUserAgent() (string, error) {
+	return GlobalModel.UserAgent.Get()
+}
+
+func SetUserAgent(val string) error {
+	return GlobalModel.UserAgent.Set(val)
+}
+
+func GetWebSearches() (string, error) {
+	return GlobalModel.WebSearches.Get()
+}
+
+func SetWebSearches(val string) error {
+	return GlobalModel.WebSearches.Set(val)
+}
+
+func GetSearXNGURL() (string, error) {
+	return GlobalModel.SearXNGURL.Get()
+}
+
+func SetSearXNGURL(val string) error {
+	return GlobalModel.SearXNGURL.Set(val)
+}
+
 func GetSortOrder() (bool, error) {
 	return GlobalModel.SortOrder.Get()
 }
@@ -101,6 +176,24 @@ func AddSortCategoryListener(listener func(string)) binding.DataListener {
 	return dataListener
 }
 
+// columns shown in the table as a comma separated list of their names
+func GetColumns() (string, error) {
+	return GlobalModel.Columns.Get()
+}
+
+func SetColumns(val string) error {
+	return GlobalModel.Columns.Set(val)
+}
+
+func AddColumnsListener(listener func(string)) binding.DataListener {
+	dataListener := binding.NewDataListener(func() {
+		val, _ := GlobalModel.Columns.Get()
+		listener(val)
+	})
+	GlobalModel.Columns.AddListener(dataListener)
+	return dataListener
+}
+
 func GetTextSize() (float64, error) {
 	return GlobalModel.TextSize.Get()
 }
@@ -179,7 +272,13 @@ func ResetProgress() error {
 	return GlobalModel.Progress.Set(0)
 }
 
+// games in a batch are processed at the same time, so increments must not be lost
+var progressMu sync.Mutex
+
 func IncrementProgress() error {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+
 	val, err := GlobalModel.Progress.Get()
 	if err != nil {
 		log.Fatal("Cannot increment progress properly", err)
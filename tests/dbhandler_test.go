@@ -1,31 +1,850 @@
 package tests
 
 import (
-// "testing"
-// "github.com/EZRA-DVLPR/GameList/internal/dbhandler"
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/EZRA-DVLPR/GameList/internal/dbhandler"
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
+	"github.com/EZRA-DVLPR/GameList/model"
 )
 
 // PERF: make tests for the following
-// 1. CreateDB
-// 2. CheckDBExists
-// 3. DeleteAllDBData
-// 4. DeleteFromDB
-// 5. AddToDB
-// 6. SearchAddToDB
-// 7. UpdateEntireDB
-// 8. UpdateGame
-// 9. ToggleFavorite
-// 10. SortDB
-// 11. Export
-//		a. CSV
-//		b. SQL
-//		c. MD
-// 12. Import
+// 1. Open
+//		a. file that was converted to db file but isn't. Eg. PDF -> db
+// 2. DeleteAllDBData
+// 3. DeleteFromDB
+// 4. UpdateEntireDB
+// 5. UpdateGame
+// 6. ToggleFavorite
+// 7. Export
 //		a. CSV
-//		b. SQL
-//		c. TXT
-// NOTE: Will need to have several databases:
-// 1. Full database with variety of data types. Will be copied from and never modified.
-// 2. Empty DB
-// 3. File that was converted to db file but isn't. Eg. PDF -> db
-// 4. Full database to modify
+//		b. MD
+// 8. Import
+//		a. TXT
+
+// opens a new DB in a temporary directory that is closed when the test ends
+func newTestStore(t *testing.T) *dbhandler.Store {
+	t.Helper()
+	return openTestStore(t, filepath.Join(t.TempDir(), "games.db"))
+}
+
+// opens the DB at path that is closed when the test ends
+func openTestStore(t *testing.T, path string) *dbhandler.Store {
+	t.Helper()
+	store, err := dbhandler.Open(path)
+	if err != nil {
+		t.Fatal("Error opening DB:", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// names of the saved games in the DB in alphabetical order
+func gameNames(t *testing.T, store *dbhandler.Store) []string {
+	t.Helper()
+	rows, err := store.SortDB()
+	if err != nil {
+		t.Fatal("Error reading games:", err)
+	}
+	var names []string
+	for _, row := range rows {
+		names = append(names, row[0])
+	}
+	slices.Sort(names)
+	return names
+}
+
+// writes the contents to a file in a temporary directory and returns its path
+func writeTestFile(t *testing.T, name string, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal("Error writing file:", err)
+	}
+	return path
+}
+
+// a game with the given name and times for Main, Main + Sides and Completionist
+func testGame(name string, main, mainPlus, comp float32) scraper.Game {
+	game := scraper.Game{Name: name, Main: main, MainPlus: mainPlus, Comp: comp}
+	game.Coop, game.Vs, game.Speedrun = -1, -1, -1
+	return game
+}
+
+func TestDBHandlerAddToDBDuplicates(t *testing.T) {
+	store := newTestStore(t)
+
+	// games whose names only differ by an edition or a word of the name are all kept
+	for _, name := range []string{
+		"Pokémon Gold Version",
+		"Pokémon Platinum Version",
+		"Dark Souls",
+		"Dark Souls Remastered",
+		"Mass Effect",
+		"Mass Effect Legendary Edition",
+		"Mafia",
+		"Mafia: Definitive Edition",
+		"DOOM Eternal",
+		"Final Fantasy VII",
+		"Mega Man X",
+		"Mega Man 10",
+	} {
+		if err := store.AddToDB(testGame(name, 10, 20, 30)); err != nil {
+			t.Errorf("Error adding %s: %v", name, err)
+		}
+	}
+
+	// the same game named with other case, accents, symbols or numerals is a duplicate
+	for _, name := range []string{"DOOM Eternal", "DOOM™ Eternal", "doom eternal", "Pokemon Gold Version", "Final Fantasy 7"} {
+		if err := store.AddToDB(testGame(name, 10, 20, 30)); !errors.Is(err, dbhandler.ErrDuplicateGame) {
+			t.Errorf("expected %s to be a duplicate, got %v", name, err)
+		}
+	}
+}
+
+// counts the requests the stand-in server gets for each path
+func countRequests(srv *httptest.Server) func(path string) int {
+	var mu sync.Mutex
+	counts := map[string]int{}
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		counts[r.URL.Path]++
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	})
+	return func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts[path]
+	}
+}
+
+func TestDBHandlerSetSourceURLs(t *testing.T) {
+	srv := newFixtureServer(t)
+	requests := countRequests(srv)
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.AddToDB(testGame("Celeste", 1, 2, 3)); err != nil {
+		t.Fatal("Error adding game:", err)
+	}
+	unchanged := func() {
+		t.Helper()
+		links, err := store.SourceURLs("Celeste")
+		if err != nil {
+			t.Fatal("Error reading links:", err)
+		}
+		if links["HLTB"] != "" || links["Completionator"] != "" {
+			t.Errorf("expected no links to be saved, got %v", links)
+		}
+	}
+
+	// a link that is not a game on the source is not fetched
+	err := store.SetSourceURLs(ctx, "Celeste", map[string]string{"HLTB": srv.URL + "/Game/Details/3441"})
+	if !errors.Is(err, dbhandler.ErrNotGamePage) {
+		t.Errorf("expected ErrNotGamePage, got %v", err)
+	}
+	unchanged()
+
+	// a page without times is not saved
+	err = store.SetSourceURLs(ctx, "Celeste", map[string]string{"HLTB": srv.URL + "/game/200"})
+	if !errors.Is(err, dbhandler.ErrNoTimeData) {
+		t.Errorf("expected ErrNoTimeData, got %v", err)
+	}
+	unchanged()
+
+	// other pages of the game and http links are saved as the page of the game
+	err = store.SetSourceURLs(ctx, "Celeste", map[string]string{
+		"HLTB":           srv.URL + "/game/42818/completions",
+		"Completionator": srv.URL + "/Game/Details/3441",
+	})
+	if err != nil {
+		t.Fatal("Error setting links:", err)
+	}
+	links, err := store.SourceURLs("Celeste")
+	if err != nil {
+		t.Fatal("Error reading links:", err)
+	}
+	if links["HLTB"] != srv.URL+"/game/42818" || links["Completionator"] != srv.URL+"/Game/Details/3441" {
+		t.Errorf("unexpected links saved: %v", links)
+	}
+
+	// the pages fetched to check the links are used to update the game
+	for _, path := range []string{"/game/42818", "/Game/Details/3441"} {
+		if n := requests(path); n != 1 {
+			t.Errorf("expected %s to be fetched once, got %d", path, n)
+		}
+	}
+	times, err := store.GameTimes("Celeste")
+	if err != nil {
+		t.Fatal("Error reading times:", err)
+	}
+	sources := map[string]bool{}
+	for _, gt := range times {
+		sources[gt.Source] = true
+	}
+	if !sources["HLTB"] || !sources["Completionator"] {
+		t.Errorf("expected times from both sources, got %v", times)
+	}
+}
+
+func TestDBHandlerExportSQLKeepsIndexes(t *testing.T) {
+	dir := t.TempDir()
+	store := openTestStore(t, filepath.Join(dir, "games.db"))
+	if err := store.AddToDB(testGame("DOOM Eternal", 1, 2, 3)); err != nil {
+		t.Fatal("Error adding game:", err)
+	}
+	if err := store.Export(2, filepath.Join(dir, "dump")); err != nil {
+		t.Fatal("Error exporting:", err)
+	}
+
+	imported := openTestStore(t, filepath.Join(dir, "imported.db"))
+	if err := imported.Import(context.Background(), 2, filepath.Join(dir, "dump.sql")); err != nil {
+		t.Fatal("Error importing:", err)
+	}
+
+	// the name key is still used to find the same game
+	if err := imported.AddToDB(testGame("DOOM™ Eternal", 1, 2, 3)); !errors.Is(err, dbhandler.ErrDuplicateGame) {
+		t.Errorf("expected a duplicate after importing, got %v", err)
+	}
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "imported.db"))
+	if err != nil {
+		t.Fatal("Error opening imported DB:", err)
+	}
+	defer db.Close()
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'games_nameKey'").Scan(&n); err != nil || n != 1 {
+		t.Errorf("expected the name key index after importing, got %d %v", n, err)
+	}
+}
+
+func TestDBHandlerImportSQL(t *testing.T) {
+	dir := t.TempDir()
+	store := openTestStore(t, filepath.Join(dir, "games.db"))
+	for _, name := range []string{"Celeste", "Hades"} {
+		if err := store.AddToDB(testGame(name, 1, 2, 3)); err != nil {
+			t.Fatal("Error adding game:", err)
+		}
+	}
+	if err := store.Export(2, filepath.Join(dir, "dump")); err != nil {
+		t.Fatal("Error exporting:", err)
+	}
+
+	// the dump replaces every game that was saved
+	imported := newTestStore(t)
+	if err := imported.AddToDB(testGame("Hollow Knight", 1, 2, 3)); err != nil {
+		t.Fatal("Error adding game:", err)
+	}
+	if err := imported.Import(context.Background(), 2, filepath.Join(dir, "dump.sql")); err != nil {
+		t.Fatal("Error importing:", err)
+	}
+	if got := gameNames(t, imported); !slices.Equal(got, []string{"Celeste", "Hades"}) {
+		t.Errorf("expected the games of the dump, got %v", got)
+	}
+}
+
+func TestDBHandlerImportSQLOldSchema(t *testing.T) {
+	// a dump made before migrations existed has only the games table
+	dump := writeTestFile(t, "old.sql", `BEGIN TRANSACTION;
+CREATE TABLE games (name TEXT PRIMARY KEY, hltburl TEXT, completionatorurl TEXT, favorite INTEGER, main REAL, mainPlus REAL, comp REAL);
+INSERT INTO games VALUES ('DOOM Eternal', '', '', 0, 22, 29, 42);
+COMMIT;
+`)
+	store := newTestStore(t)
+	if err := store.Import(context.Background(), 2, dump); err != nil {
+		t.Fatal("Error importing:", err)
+	}
+
+	version, err := store.SchemaVersion()
+	if err != nil || version == 0 {
+		t.Errorf("expected the dump to be migrated, got version %d %v", version, err)
+	}
+	if got := gameNames(t, store); !slices.Equal(got, []string{"DOOM Eternal"}) {
+		t.Errorf("expected the game of the dump, got %v", got)
+	}
+	if err := store.AddToDB(testGame("DOOM™ Eternal", 1, 2, 3)); !errors.Is(err, dbhandler.ErrDuplicateGame) {
+		t.Errorf("expected the imported game to have a name key, got %v", err)
+	}
+}
+
+func TestDBHandlerImportSQLRekeysNames(t *testing.T) {
+	dir := t.TempDir()
+	store := openTestStore(t, filepath.Join(dir, "games.db"))
+	if err := store.AddToDB(testGame("Final Fantasy VII", 1, 2, 3)); err != nil {
+		t.Fatal("Error adding game:", err)
+	}
+	if err := store.SaveAlias(dbhandler.Alias{Alias: "FINAL FANTASY VII REMAKE", SearchName: "Final Fantasy 7 Remake"}); err != nil {
+		t.Fatal("Error saving alias:", err)
+	}
+	if err := store.Export(2, filepath.Join(dir, "dump")); err != nil {
+		t.Fatal("Error exporting:", err)
+	}
+	dump, err := os.ReadFile(filepath.Join(dir, "dump.sql"))
+	if err != nil {
+		t.Fatal("Error reading dump:", err)
+	}
+
+	// a dump from before roman numerals were part of the name keys
+	old := writeTestFile(t, "v10.sql", string(dump)+`UPDATE schema_version SET version = 10;
+UPDATE games SET nameKey = 'final fantasy vii';
+UPDATE game_aliases SET aliasKey = 'final fantasy vii remake';
+`)
+	imported := newTestStore(t)
+	if err := imported.Import(context.Background(), 2, old); err != nil {
+		t.Fatal("Error importing:", err)
+	}
+	if err := imported.AddToDB(testGame("Final Fantasy 7", 1, 2, 3)); !errors.Is(err, dbhandler.ErrDuplicateGame) {
+		t.Errorf("expected the imported game to be keyed again, got %v", err)
+	}
+	if err := imported.DeleteAlias("Final Fantasy 7 Remake"); err != nil {
+		t.Errorf("expected the imported alias to be keyed again, got %v", err)
+	}
+}
+
+func TestDBHandlerImportSQLMalformed(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.AddToDB(testGame("Celeste", 1, 2, 3)); err != nil {
+		t.Fatal("Error adding game:", err)
+	}
+
+	// the dump fails after its first table is made
+	dump := writeTestFile(t, "broken.sql", `BEGIN TRANSACTION;
+CREATE TABLE games (name TEXT PRIMARY KEY);
+INSERT INTO no_such_table VALUES (1);
+COMMIT;
+`)
+	if err := store.Import(context.Background(), 2, dump); err == nil {
+		t.Fatal("expected an error importing a malformed dump")
+	}
+
+	// nothing saved before the import is lost, and the DB can still be used
+	if got := gameNames(t, store); !slices.Equal(got, []string{"Celeste"}) {
+		t.Errorf("expected the saved games to be kept, got %v", got)
+	}
+	if err := store.AddToDB(testGame("Hades", 1, 2, 3)); err != nil {
+		t.Errorf("Error adding game after a failed import: %v", err)
+	}
+}
+
+func TestDBHandlerImportSQLTooNew(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "games.db")
+	store := openTestStore(t, path)
+	if err := store.AddToDB(testGame("Celeste", 1, 2, 3)); err != nil {
+		t.Fatal("Error adding game:", err)
+	}
+
+	// a dump from a newer version of GameList
+	if err := store.Export(2, filepath.Join(dir, "dump")); err != nil {
+		t.Fatal("Error exporting:", err)
+	}
+	dump, err := os.ReadFile(filepath.Join(dir, "dump.sql"))
+	if err != nil {
+		t.Fatal("Error reading dump:", err)
+	}
+	newer := writeTestFile(t, "newer.sql", string(dump)+"UPDATE schema_version SET version = 999;\n")
+
+	if err := store.Import(context.Background(), 2, newer); !errors.Is(err, dbhandler.ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got %v", err)
+	}
+
+	// the DB is kept as it was and can still be opened
+	if got := gameNames(t, store); !slices.Equal(got, []string{"Celeste"}) {
+		t.Errorf("expected the saved games to be kept, got %v", got)
+	}
+	store.Close()
+	reopened := openTestStore(t, path)
+	if got := gameNames(t, reopened); !slices.Equal(got, []string{"Celeste"}) {
+		t.Errorf("expected the saved games after opening the DB again, got %v", got)
+	}
+}
+
+func TestDBHandlerImportCSV(t *testing.T) {
+	dir := t.TempDir()
+	store := openTestStore(t, filepath.Join(dir, "games.db"))
+	for _, name := range []string{"Celeste", "Hades"} {
+		if err := store.AddToDB(testGame(name, 1, 2, 3)); err != nil {
+			t.Fatal("Error adding game:", err)
+		}
+	}
+	if err := store.Export(1, filepath.Join(dir, "games")); err != nil {
+		t.Fatal("Error exporting:", err)
+	}
+
+	imported := newTestStore(t)
+	if err := imported.Import(context.Background(), 1, filepath.Join(dir, "games.csv")); err != nil {
+		t.Fatal("Error importing:", err)
+	}
+	if got := gameNames(t, imported); !slices.Equal(got, []string{"Celeste", "Hades"}) {
+		t.Errorf("expected the games of the CSV, got %v", got)
+	}
+}
+
+func TestDBHandlerImportCSVWithoutKeys(t *testing.T) {
+	// a CSV made before games had a name key
+	csv := writeTestFile(t, "games.csv", `name,main,mainPlus,comp
+DOOM Eternal,22,29,42
+Celeste,8,13,37
+`)
+	store := newTestStore(t)
+	if err := store.Import(context.Background(), 1, csv); err != nil {
+		t.Fatal("Error importing:", err)
+	}
+	if err := store.AddToDB(testGame("DOOM™ Eternal", 1, 2, 3)); !errors.Is(err, dbhandler.ErrDuplicateGame) {
+		t.Errorf("expected the imported game to have a name key, got %v", err)
+	}
+}
+
+func TestDBHandlerMergeStrategies(t *testing.T) {
+	srv := newFixtureServer(t)
+	store := newTestStore(t)
+	t.Cleanup(func() { model.SetMergeStrategy("") })
+
+	// HLTB reports how many submissions it has and Completionator does not
+	if err := store.AddToDB(testGame("Celeste", 1, 2, 3)); err != nil {
+		t.Fatal("Error adding game:", err)
+	}
+	err := store.SetSourceURLs(context.Background(), "Celeste", map[string]string{
+		"HLTB":           srv.URL + "/game/42818",
+		"Completionator": srv.URL + "/Game/Details/3441",
+	})
+	if err != nil {
+		t.Fatal("Error setting links:", err)
+	}
+
+	// HLTB has 8.5 and 37.5 hours, Completionator has 9 and 40 hours
+	tests := []struct {
+		strategy   string
+		main, comp string
+	}{
+		{dbhandler.MergeMax, "9", "40"},
+		{dbhandler.MergeMin, "8.5", "37.5"},
+		{dbhandler.MergeMean, "8.75", "38.75"},
+		{dbhandler.MergeMedian, "8.75", "38.75"},
+		// the time of a source without submissions is not left out
+		{dbhandler.MergeWeighted, "8.75", "38.75"},
+		{dbhandler.MergePrefer("HLTB"), "8.5", "37.5"},
+		{dbhandler.MergePrefer("Completionator"), "9", "40"},
+	}
+	for _, tt := range tests {
+		model.SetMergeStrategy(tt.strategy)
+		if err := store.RecomputeMergedTimes(); err != nil {
+			t.Fatal("Error recomputing times:", err)
+		}
+		rows, err := store.SortDB()
+		if err != nil {
+			t.Fatal("Error reading games:", err)
+		}
+		if main, comp := rows[0][1], rows[0][3]; main != tt.main || comp != tt.comp {
+			t.Errorf("%s: expected %s and %s hours, got %s and %s", tt.strategy, tt.main, tt.comp, main, comp)
+		}
+	}
+}
+
+func TestDBHandlerAliases(t *testing.T) {
+	srv := newFixtureServer(t)
+	requests := countRequests(srv)
+	store := newTestStore(t)
+	ctx := context.Background()
+	model.SetSearchSource("HLTB")
+	t.Cleanup(func() { model.SetSearchSource("") })
+
+	if err := store.SaveAlias(dbhandler.Alias{Alias: "  "}); !errors.Is(err, dbhandler.ErrUnknownOption) {
+		t.Errorf("expected ErrUnknownOption for an alias without a name, got %v", err)
+	}
+
+	// saving the alias again under a differently written name replaces it
+	link := srv.URL + "/game/100"
+	for _, alias := range []dbhandler.Alias{
+		{Alias: "Pokémon Gold Version", SearchName: "Pokemon Gold"},
+		{Alias: "POKEMON GOLD VERSION", SearchName: "Pokemon Gold", URLs: map[string]string{"HLTB": link}},
+	} {
+		if err := store.SaveAlias(alias); err != nil {
+			t.Fatal("Error saving alias:", err)
+		}
+	}
+	aliases, err := store.Aliases()
+	if err != nil {
+		t.Fatal("Error reading aliases:", err)
+	}
+	if len(aliases) != 1 || aliases[0].Alias != "POKEMON GOLD VERSION" || aliases[0].URLs["HLTB"] != link {
+		t.Fatalf("expected the alias to be replaced, got %v", aliases)
+	}
+
+	// the alias is found by its normalized name and its link is used without searching
+	candidates, err := store.SearchCandidates(ctx, "Pokémon Gold Version")
+	if err != nil {
+		t.Fatal("Error searching:", err)
+	}
+	if got := candidates["HLTB"]; len(got) != 1 || got[0].URL != link || got[0].Title != "Pokemon Gold" {
+		t.Errorf("expected the link of the alias, got %v", got)
+	}
+	if n := requests("/api/search"); n != 0 {
+		t.Errorf("expected no search, got %d", n)
+	}
+
+	// a game whose name only shares words with the alias is searched for
+	candidates, err = store.SearchCandidates(ctx, "Pokémon")
+	if err != nil {
+		t.Fatal("Error searching:", err)
+	}
+	if got := candidates["HLTB"]; len(got) == 0 || got[0].URL == link {
+		t.Errorf("expected the alias not to be used, got %v", got)
+	}
+	if n := requests("/api/search"); n != 1 {
+		t.Errorf("expected a search, got %d", n)
+	}
+
+	if err := store.DeleteAlias("pokemon gold version"); err != nil {
+		t.Fatal("Error deleting alias:", err)
+	}
+	if aliases, err := store.Aliases(); err != nil || len(aliases) != 0 {
+		t.Errorf("expected no aliases, got %v %v", aliases, err)
+	}
+	if err := store.DeleteAlias("Pokémon Gold Version"); !errors.Is(err, dbhandler.ErrGameNotFound) {
+		t.Errorf("expected ErrGameNotFound for a removed alias, got %v", err)
+	}
+}
+
+// saves an alias for each game name that links its HLTB page to path on the stand-in server
+func saveHLTBAliases(t *testing.T, store *dbhandler.Store, srv *httptest.Server, paths map[string]string) {
+	t.Helper()
+	for gameName, path := range paths {
+		if err := store.SaveAlias(dbhandler.Alias{Alias: gameName, URLs: map[string]string{"HLTB": srv.URL + path}}); err != nil {
+			t.Fatal("Error saving alias:", err)
+		}
+	}
+}
+
+func TestDBHandlerSearchAddAll(t *testing.T) {
+	srv := newFixtureServer(t)
+	store := newTestStore(t)
+	model.SetSearchSource("HLTB")
+	model.SetConcurrency(3)
+	t.Cleanup(func() {
+		model.SetSearchSource("")
+		model.SetConcurrency(0)
+	})
+
+	saveHLTBAliases(t, store, srv, map[string]string{
+		"Celeste":      "/game/42818",
+		"It Takes Two": "/game/100",
+		"No Times":     "/game/200",
+		"Missing Page": "/game/404",
+		"Removed Game": "/game/405",
+	})
+
+	// a game that fails does not stop the others, and every failure is returned
+	err := store.SearchAddAll(context.Background(), []string{"Celeste", "No Times", "Missing Page", "It Takes Two", "Removed Game"})
+	if !errors.Is(err, dbhandler.ErrNoTimeData) || !errors.Is(err, scraper.ErrSourceUnavailable) {
+		t.Errorf("expected the errors of the games that failed, got %v", err)
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 3 {
+		t.Errorf("expected an error for each of the 3 games that failed, got %v", err)
+	}
+	if got := gameNames(t, store); !slices.Equal(got, []string{"Celeste", "It Takes Two"}) {
+		t.Errorf("expected the games that were found to be added, got %v", got)
+	}
+}
+
+func TestDBHandlerSearchAddAllCancelled(t *testing.T) {
+	srv := newFixtureServer(t)
+	requests := countRequests(srv)
+	store := newTestStore(t)
+	model.SetSearchSource("HLTB")
+	model.SetConcurrency(1)
+	t.Cleanup(func() {
+		model.SetSearchSource("")
+		model.SetConcurrency(0)
+	})
+
+	// the second game is held until the batch is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/game/100" {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	saveHLTBAliases(t, store, srv, map[string]string{
+		"Celeste":        "/game/42818",
+		"It Takes Two":   "/game/100",
+		"Celeste Again":  "/game/42818",
+		"Celeste Deluxe": "/game/42818",
+	})
+	err := store.SearchAddAll(ctx, []string{"Celeste", "It Takes Two", "Celeste Again", "Celeste Deluxe"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	// the game added before the cancel is kept, and no other game is started
+	if n := requests("/game/42818"); n != 1 {
+		t.Errorf("expected only the first game to be fetched, got %d", n)
+	}
+	if got := gameNames(t, store); !slices.Equal(got, []string{"Celeste"}) {
+		t.Errorf("expected only the first game to be added, got %v", got)
+	}
+}
+
+func TestDBHandlerSearchAddAllOn(t *testing.T) {
+	newFixtureServer(t)
+	store := newTestStore(t)
+	model.SetSearchSource("HLTB")
+	t.Cleanup(func() { model.SetSearchSource("") })
+
+	// a single source saves the game under the name on the site, not the name of the store
+	if err := store.SearchAddAllOn(context.Background(), []string{"Celeste Complete Edition"}, "PC"); err != nil {
+		t.Fatal("Error adding games:", err)
+	}
+	if got := gameNames(t, store); !slices.Equal(got, []string{"Celeste"}) {
+		t.Fatalf("expected the name on the site, got %v", got)
+	}
+	if platform, err := store.MyPlatform("Celeste"); err != nil || platform != "PC" {
+		t.Errorf("expected the platform of the store, got %q %v", platform, err)
+	}
+
+	// a platform that was already set is kept
+	if err := store.SearchAddAllOn(context.Background(), []string{"Celeste"}, "Nintendo Switch"); !errors.Is(err, dbhandler.ErrDuplicateGame) {
+		t.Errorf("expected ErrDuplicateGame, got %v", err)
+	}
+	if platform, err := store.MyPlatform("Celeste"); err != nil || platform != "PC" {
+		t.Errorf("expected the platform to be kept, got %q %v", platform, err)
+	}
+}
+
+func TestDBHandlerMyPlatformTimes(t *testing.T) {
+	srv := newFixtureServer(t)
+	store := newTestStore(t)
+
+	if err := store.SetMyPlatform("Celeste", "PC"); !errors.Is(err, dbhandler.ErrGameNotFound) {
+		t.Errorf("expected ErrGameNotFound for a game that is not saved, got %v", err)
+	}
+	if err := store.AddToDB(testGame("Celeste", 1, 2, 3)); err != nil {
+		t.Fatal("Error adding game:", err)
+	}
+	err := store.SetSourceURLs(context.Background(), "Celeste", map[string]string{"HLTB": srv.URL + "/game/42818"})
+	if err != nil {
+		t.Fatal("Error setting links:", err)
+	}
+
+	// the table shows the times on the platform the game is owned on, and the times across every platform o/w
+	tests := []struct {
+		platform       string
+		main, mainPlus string
+		comp           string
+	}{
+		{"", "8.5", "13", "37.5"},
+		{"PC", "8.666667", "13.333333", "38"},
+		{"Nintendo", "8.166667", "12", "36"},
+		// a platform without a time for a category has the time across every platform for it
+		{"PlayStation", "9", "14", "37.5"},
+		{"Xbox", "8.5", "13", "37.5"},
+	}
+	for _, tt := range tests {
+		if err := store.SetMyPlatform("Celeste", tt.platform); err != nil {
+			t.Fatal("Error setting platform:", err)
+		}
+		if platform, err := store.MyPlatform("Celeste"); err != nil || platform != tt.platform {
+			t.Errorf("expected platform %q, got %q %v", tt.platform, platform, err)
+		}
+		rows, err := store.SortDB()
+		if err != nil {
+			t.Fatal("Error reading games:", err)
+		}
+		if got := rows[0][1:4]; !slices.Equal(got, []string{tt.main, tt.mainPlus, tt.comp}) {
+			t.Errorf("%q: expected %s, %s and %s hours, got %v", tt.platform, tt.main, tt.mainPlus, tt.comp, got)
+		}
+	}
+}
+
+func TestDBHandlerSortDB(t *testing.T) {
+	store := newTestStore(t)
+	model.SetColumns("name,main,releaseDate,coverURL")
+	model.SetSortOrder(true)
+	t.Cleanup(func() {
+		model.SetColumns("")
+		model.SetSortCategory("")
+		model.SetSortOrder(false)
+	})
+
+	for _, game := range []struct {
+		name, releaseDate string
+		main              float32
+	}{
+		{"Celeste", "2018-01-25", 8.5},
+		{"Hollow Knight", "2017-02-24", 8.25},
+		{"9 Monkeys", "2018-11-20", 12},
+		{"10 Second Ninja", "2018-05-02", 8.75},
+	} {
+		g := testGame(game.name, game.main, -1, -1)
+		g.ReleaseDate = game.releaseDate
+		g.CoverURL = "https://example.com/" + game.name + ".jpg"
+		if err := store.AddToDB(g); err != nil {
+			t.Fatal("Error adding game:", err)
+		}
+	}
+
+	// release dates of the same year are in order, times are sorted with their fractions
+	// and names that start with a number sort by it
+	tests := []struct {
+		category string
+		want     []string
+	}{
+		{"releaseDate", []string{"Hollow Knight", "Celeste", "10 Second Ninja", "9 Monkeys"}},
+		{"main", []string{"Hollow Knight", "Celeste", "10 Second Ninja", "9 Monkeys"}},
+		{"name", []string{"9 Monkeys", "10 Second Ninja", "Celeste", "Hollow Knight"}},
+	}
+	for _, tt := range tests {
+		model.SetSortCategory(tt.category)
+		rows, err := store.SortDB()
+		if err != nil {
+			t.Fatal("Error reading games:", err)
+		}
+		var got []string
+		for _, row := range rows {
+			got = append(got, row[0])
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("sorted by %s: expected %v, got %v", tt.category, tt.want, got)
+		}
+	}
+
+	// the cover is one of the columns that can be shown
+	rows, err := store.SortDB()
+	if err != nil {
+		t.Fatal("Error reading games:", err)
+	}
+	if got := rows[0][3]; got != "https://example.com/9 Monkeys.jpg" {
+		t.Errorf("expected the link to the cover, got %q", got)
+	}
+}
+
+func TestDBHandlerGameTimes(t *testing.T) {
+	srv := newFixtureServer(t)
+	store := newTestStore(t)
+	model.SetColumns("name,main,coop,speedrun")
+	model.SetMergeStrategy(dbhandler.MergeMean)
+	t.Cleanup(func() {
+		model.SetColumns("")
+		model.SetMergeStrategy("")
+	})
+
+	if err := store.AddToDB(testGame("Celeste", 1, 2, 3)); err != nil {
+		t.Fatal("Error adding game:", err)
+	}
+	err := store.SetSourceURLs(context.Background(), "Celeste", map[string]string{
+		"HLTB":           srv.URL + "/game/42818",
+		"Completionator": srv.URL + "/Game/Details/3441",
+	})
+	if err != nil {
+		t.Fatal("Error setting links:", err)
+	}
+
+	// each source keeps its own times. HLTB has no speedrun time (-1), so none is saved for it
+	times, err := store.GameTimes("Celeste")
+	if err != nil {
+		t.Fatal("Error reading times:", err)
+	}
+	got := map[string]float32{}
+	polled := map[string]int{}
+	for _, gt := range times {
+		got[gt.Source+" "+gt.Category] = gt.Value
+		polled[gt.Source+" "+gt.Category] = gt.Polled
+	}
+	want := map[string]float32{
+		"HLTB main": 8.5, "HLTB mainPlus": 13, "HLTB comp": 37.5,
+		"Completionator main": 9, "Completionator mainPlus": 14.5, "Completionator comp": 40, "Completionator speedrun": 1,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("expected the times of each source %v, got %v", want, got)
+	}
+	if polled["HLTB main"] != 3000 {
+		t.Errorf("expected the number of submissions of HLTB, got %d", polled["HLTB main"])
+	}
+
+	// a time only one source has is that time, and a time no source has stays -1
+	checkMerged := func() {
+		t.Helper()
+		rows, err := store.SortDB()
+		if err != nil {
+			t.Fatal("Error reading games:", err)
+		}
+		if row := rows[0][1:]; !slices.Equal(row, []string{"8.75", "-1", "1"}) {
+			t.Errorf("expected the merged main, co-op and speedrun times, got %v", row)
+		}
+	}
+	checkMerged()
+
+	// the merged times are the same when they are computed again from the saved times
+	if err := store.RecomputeMergedTimes(); err != nil {
+		t.Fatal("Error recomputing times:", err)
+	}
+	checkMerged()
+}
+
+func TestDBHandlerLowConfidenceTimes(t *testing.T) {
+	srv := newFixtureServer(t)
+	model.SetSortCategory(scraper.CategoryComp)
+	model.SetSortOrder(true)
+	t.Cleanup(func() {
+		model.SetSortCategory("")
+		model.SetSortOrder(false)
+	})
+
+	// the page of a game whose completionist time only 3 players submitted
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/game/7" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		page := strings.Replace(string(readFixture(t, "hltb_game.html", srv.URL)), "<td>998</td>", "<td>3</td>", 1)
+		w.Write([]byte(page))
+	})
+
+	store := newTestStore(t)
+	for _, game := range []scraper.Game{testGame("Celeste", 8, 13, 50), testGame("Celeste Few", 1, 2, 3)} {
+		if err := store.AddToDB(game); err != nil {
+			t.Fatal("Error adding game:", err)
+		}
+	}
+
+	// Completionator does not report the number of submissions, so it does not add to them
+	err := store.SetSourceURLs(context.Background(), "Celeste Few", map[string]string{
+		"HLTB":           srv.URL + "/game/7",
+		"Completionator": srv.URL + "/Game/Details/3441",
+	})
+	if err != nil {
+		t.Fatal("Error setting links:", err)
+	}
+
+	// only the time from few players is marked, and the mark does not change how the times are sorted
+	rows, err := store.SortDB()
+	if err != nil {
+		t.Fatal("Error reading games:", err)
+	}
+	want := [][]string{
+		{"Celeste Few", "9", "14.5", "40" + dbhandler.LowConfidenceMark},
+		{"Celeste", "8", "13", "50"},
+	}
+	if !slices.EqualFunc(rows, want, slices.Equal) {
+		t.Errorf("expected %v, got %v", want, rows)
+	}
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/EZRA-DVLPR/GameList/internal/normalize"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Celeste", "Celeste"},
+		{"DOOM™ Eternal - Deluxe Edition (PS4 & PS5)", "DOOM Eternal"},
+		{"The Witcher 3: Wild Hunt – Game of the Year Edition", "The Witcher 3: Wild Hunt"},
+		{"Death Stranding Director's Cut", "Death Stranding"},
+		{"Horizon Zero Dawn Complete Edition for PC", "Horizon Zero Dawn"},
+		{"Sekiro: Shadows Die Twice - GOTY", "Sekiro: Shadows Die Twice"},
+		{"Hades [Nintendo Switch]", "Hades"},
+		{"Hades [Switch]", "Hades"},
+		{"Mafia: Definitive Edition", "Mafia"},
+		{"Celeste Nintendo Switch", "Celeste"},
+		{"Celeste - Switch", "Celeste"},
+		{"Celeste - PS4 & Switch", "Celeste"},
+		// words that are part of the name of the game are kept
+		{"Pokémon Gold Version", "Pokémon Gold Version"},
+		{"Pokémon Platinum Version", "Pokémon Platinum Version"},
+		{"Mass Effect Legendary Edition", "Mass Effect Legendary Edition"},
+		{"Dark Souls Remastered", "Dark Souls Remastered"},
+		{"Bait and Switch", "Bait and Switch"},
+		{"Light Switch", "Light Switch"},
+		// nothing would be left, so the name is kept
+		{"Deluxe Edition", "Deluxe Edition"},
+		{"  Spaced   Out  ", "Spaced Out"},
+	}
+	for _, tt := range tests {
+		if got := normalize.Query(tt.in); got != tt.want {
+			t.Errorf("Query(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Celeste", "celeste"},
+		{"DOOM™ Eternal", "doom eternal"},
+		{"Pokémon: Let's Go, Pikachu!", "pokemon lets go pikachu"},
+		{"Assassin’s Creed", "assassins creed"},
+		{"Final Fantasy VII", "final fantasy 7"},
+		{"Rocky XX", "rocky 20"},
+		{"Civilization VI: Gathering Storm", "civilization 6 gathering storm"},
+		{"Mega Man X", "mega man x"},
+		{"Halo: Combat Evolved", "halo combat evolved"},
+		{"Pokémon Gold Version", "pokemon gold version"},
+		{"Dark Souls Remastered", "dark souls remastered"},
+		{"Mafia: Definitive Edition", "mafia definitive edition"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalize.Key(tt.in); got != tt.want {
+			t.Errorf("Key(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeKeyTellsGamesApart(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"DOOM™ Eternal", "Doom Eternal", true},
+		{"Pokémon Gold Version", "pokemon gold version", true},
+		{"Pokémon Gold Version", "Pokémon Platinum Version", false},
+		{"Mass Effect Legendary Edition", "Mass Effect", false},
+		{"Dark Souls Remastered", "Dark Souls", false},
+		{"Mafia: Definitive Edition", "Mafia", false},
+		{"Final Fantasy VII", "Final Fantasy 7", true},
+		{"Rocky II", "Rocky 2", true},
+		{"Final Fantasy VII", "Final Fantasy VIII", false},
+		{"Mega Man X", "Mega Man 10", false},
+	}
+	for _, tt := range tests {
+		if got := normalize.Equal(tt.a, tt.b); got != tt.same {
+			t.Errorf("Equal(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
+
+func TestNormalizeSimilarity(t *testing.T) {
+	// roman numerals and editions do not lower the score when ranking results
+	if got := normalize.Similarity("Final Fantasy VII", "FINAL FANTASY 7 - Deluxe Edition"); got != 1 {
+		t.Errorf("expected the same game to score 1, got %v", got)
+	}
+	if got := normalize.Similarity("Doom", "Doom Eternal"); got < 0.66 || got > 0.67 {
+		t.Errorf("expected a partial match to score 0.67, got %v", got)
+	}
+}
@@ -1,12 +1,670 @@
 package tests
 
 import (
-// "testing"
-// "github.com/EZRA-DVLPR/GameList/internal/scraper"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/EZRA-DVLPR/GameList/internal/scraper"
+	"gopkg.in/yaml.v3"
 )
 
-// PERF: make tests for the following
-// 1. SearchGameHLTB
-// 2. FetchHLTB
-// 3. SearchGameCompletionator
-// 4. FetchCompletionator
+// INFO: run `go test ./tests -run Scraper -update` to rewrite the golden files after a fixture changes
+var update = flag.Bool("update", false, "rewrite the golden files with the current results")
+
+// saved pages of each site are in testdata/scraper, and what is expected from them in testdata/scraper/golden
+const fixtureDir = "testdata/scraper"
+
+// placeholder for the address of the stand-in server in fixtures and golden files
+const baseHolder = "{{base}}"
+
+// starts a stand-in for every site that serves the saved pages and points the scraper at it
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	routes := map[string]string{
+		"/game/42818":        "hltb_game.html",
+		"/game/100":          "hltb_game_coop.html",
+		"/game/200":          "hltb_game_notimes.html",
+		"/game/300":          "hltb_game_changed.html",
+		"/Game/Details/3441": "completionator_game.html",
+		"/api/search":        "hltb_search.json",
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(readFixture(t, name, srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+
+	// every site is the stand-in
+	scraper.SetBaseURLs(scraper.BaseURLs{HLTB: srv.URL, Completionator: srv.URL, Bing: srv.URL, DuckDuckGo: srv.URL, SearXNG: srv.URL})
+	t.Cleanup(func() {
+		scraper.SetBaseURLs(scraper.DefaultBaseURLs)
+	})
+	useTestLimits(t)
+	return srv
+}
+
+// no page is cached, and requests are neither spaced out nor wait before a retry
+func useTestLimits(t *testing.T) {
+	t.Helper()
+	scraper.SetHostLimit(scraper.DefaultHostParallelism, 0)
+	scraper.SetRetry(scraper.DefaultRetries, 0)
+	if err := scraper.SetCache("", scraper.DefaultCacheTTL); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		scraper.SetHostLimit(scraper.DefaultHostParallelism, scraper.DefaultHostDelay)
+		scraper.SetRetry(scraper.DefaultRetries, scraper.DefaultBackoff)
+	})
+}
+
+// returns the saved page with links pointed at base
+func readFixture(t *testing.T, name string, base string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(fixtureDir, name))
+	if err != nil {
+		t.Fatal("Error reading fixture:", err)
+	}
+	return []byte(strings.ReplaceAll(string(data), baseHolder, base))
+}
+
+// compares got with the golden file of the given name. links to base are saved as the placeholder
+func checkGolden(t *testing.T, name string, base string, got any) {
+	t.Helper()
+	data, err := json.MarshalIndent(got, "", "\t")
+	if err != nil {
+		t.Fatal("Error encoding result:", err)
+	}
+	data = append([]byte(strings.ReplaceAll(string(data), base, baseHolder)), '\n')
+
+	path := filepath.Join(fixtureDir, "golden", name+".json")
+	if *update {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal("Error writing golden file:", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("Error reading golden file:", err)
+	}
+	if string(want) != string(data) {
+		t.Errorf("result does not match %s\ngot:\n%s\nwant:\n%s", path, data, want)
+	}
+}
+
+func TestScraperFetchHLTB(t *testing.T) {
+	srv := newFixtureServer(t)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"hltb_game", "/game/42818"},
+		// "Co-Op" and "Vs." are kept apart from the main story
+		{"hltb_game_coop", "/game/100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, err := scraper.FetchHLTB(context.Background(), srv.URL+tt.path)
+			if err != nil {
+				t.Fatal("Error fetching game:", err)
+			}
+			checkGolden(t, tt.name, srv.URL, game)
+		})
+	}
+}
+
+func TestScraperFetchHLTBNoTimes(t *testing.T) {
+	srv := newFixtureServer(t)
+
+	game, err := scraper.FetchHLTB(context.Background(), srv.URL+"/game/200")
+	if !errors.Is(err, scraper.ErrNoTimeData) {
+		t.Fatalf("expected ErrNoTimeData, got %v", err)
+	}
+	if game.Main != -1 || game.MainPlus != -1 || game.Comp != -1 {
+		t.Errorf("expected every time to be -1, got %+v", game)
+	}
+}
+
+func TestScraperFetchLayoutChanged(t *testing.T) {
+	srv := newFixtureServer(t)
+
+	var told []map[string]error
+	scraper.AddBrokenSourcesListener(func(broken map[string]error) {
+		told = append(told, broken)
+	})
+
+	// the times are there, but not where the selectors look for them
+	if _, err := scraper.FetchHLTB(context.Background(), srv.URL+"/game/300"); !errors.Is(err, scraper.ErrLayoutChanged) {
+		t.Fatalf("expected ErrLayoutChanged, got %v", err)
+	}
+	if _, ok := scraper.BrokenSources()["HLTB"]; !ok {
+		t.Errorf("expected HLTB to be broken, got %v", scraper.BrokenSources())
+	}
+
+	// a page that matches again means the site works again
+	if _, err := scraper.FetchHLTB(context.Background(), srv.URL+"/game/42818"); err != nil {
+		t.Fatal("Error fetching game:", err)
+	}
+	if len(scraper.BrokenSources()) != 0 {
+		t.Errorf("expected no broken sites, got %v", scraper.BrokenSources())
+	}
+	if len(told) != 2 || len(told[0]) != 1 || len(told[1]) != 0 {
+		t.Errorf("expected to be told HLTB broke then worked again, got %v", told)
+	}
+}
+
+func TestScraperFetchCompletionator(t *testing.T) {
+	srv := newFixtureServer(t)
+
+	game, err := scraper.FetchCompletionator(context.Background(), srv.URL+"/Game/Details/3441")
+	if err != nil {
+		t.Fatal("Error fetching game:", err)
+	}
+	checkGolden(t, "completionator_game", srv.URL, game)
+}
+
+func TestScraperFetchUnavailable(t *testing.T) {
+	srv := newFixtureServer(t)
+
+	if _, err := scraper.FetchHLTB(context.Background(), srv.URL+"/game/404"); !errors.Is(err, scraper.ErrSourceUnavailable) {
+		t.Errorf("HLTB: expected ErrSourceUnavailable, got %v", err)
+	}
+	if _, err := scraper.FetchCompletionator(context.Background(), srv.URL+"/Game/Details/404"); !errors.Is(err, scraper.ErrSourceUnavailable) {
+		t.Errorf("Completionator: expected ErrSourceUnavailable, got %v", err)
+	}
+}
+
+func TestScraperFetchRetry(t *testing.T) {
+	useTestLimits(t)
+
+	// the site is busy for the first two requests
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		switch requests.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write(readFixture(t, "hltb_game.html", ""))
+		}
+	}))
+	defer srv.Close()
+
+	game, err := scraper.FetchHLTB(context.Background(), srv.URL+"/game/42818")
+	if err != nil {
+		t.Fatal("Error fetching game:", err)
+	}
+	if game.Main <= 0 || requests.Load() != 3 {
+		t.Errorf("expected the game after 3 requests, got main %v after %d", game.Main, requests.Load())
+	}
+}
+
+func TestScraperFetchRetryGivesUp(t *testing.T) {
+	useTestLimits(t)
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	if _, err := scraper.FetchHLTB(context.Background(), srv.URL+"/game/42818"); !errors.Is(err, scraper.ErrSourceUnavailable) {
+		t.Errorf("expected ErrSourceUnavailable, got %v", err)
+	}
+	if requests.Load() != scraper.DefaultRetries+1 {
+		t.Errorf("expected %d requests, got %d", scraper.DefaultRetries+1, requests.Load())
+	}
+}
+
+func TestScraperRobotsDisallowed(t *testing.T) {
+	useTestLimits(t)
+
+	var visited atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /game/\n"))
+			return
+		}
+		visited.Store(true)
+		w.Write(readFixture(t, "hltb_game.html", ""))
+	}))
+	defer srv.Close()
+
+	if _, err := scraper.FetchHLTB(context.Background(), srv.URL+"/game/42818"); !errors.Is(err, scraper.ErrDisallowed) {
+		t.Errorf("expected ErrDisallowed, got %v", err)
+	}
+	if visited.Load() {
+		t.Error("expected the page not to be requested")
+	}
+}
+
+func TestScraperBrowserRobotsDisallowed(t *testing.T) {
+	useTestLimits(t)
+
+	var visited atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /search\n"))
+			return
+		}
+		visited.Store(true)
+	}))
+	defer srv.Close()
+
+	// the browser is never started for a page the site does not allow
+	if err := scraper.RunBrowser(context.Background(), srv.URL+"/search?q=Celeste"); !errors.Is(err, scraper.ErrDisallowed) {
+		t.Errorf("expected ErrDisallowed, got %v", err)
+	}
+	if visited.Load() {
+		t.Error("expected the page not to be requested")
+	}
+}
+
+func TestScraperUserAgent(t *testing.T) {
+	useTestLimits(t)
+	scraper.SetUserAgent("GameList-test/1.0")
+	defer scraper.SetUserAgent("")
+
+	var agents []string
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents = append(agents, r.UserAgent())
+		mu.Unlock()
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(readFixture(t, "hltb_game.html", ""))
+	}))
+	defer srv.Close()
+
+	if _, err := scraper.FetchHLTB(context.Background(), srv.URL+"/game/42818"); err != nil {
+		t.Fatal("Error fetching game:", err)
+	}
+	for _, agent := range agents {
+		if agent != "GameList-test/1.0" {
+			t.Errorf("expected every request to send the user agent, got %q", agent)
+		}
+	}
+	if scraper.SetUserAgent(""); scraper.CurrentUserAgent() != scraper.DefaultUserAgent {
+		t.Errorf("expected an empty user agent to give the default, got %q", scraper.CurrentUserAgent())
+	}
+}
+
+func TestScraperFetchCancelled(t *testing.T) {
+	srv := newFixtureServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := scraper.FetchHLTB(ctx, srv.URL+"/game/42818"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestScraperSearchCandidatesHLTB(t *testing.T) {
+	srv := newFixtureServer(t)
+
+	candidates, err := scraper.SearchCandidatesHLTB(context.Background(), "Celeste")
+	if err != nil {
+		t.Fatal("Error searching:", err)
+	}
+	checkGolden(t, "hltb_search", srv.URL, candidates)
+}
+
+func TestScraperExtractCandidates(t *testing.T) {
+	const base = "http://fixture.test"
+
+	tests := []struct {
+		name    string
+		fixture string
+		extract func(pageHTML string, base string) []scraper.Candidate
+	}{
+		{"hltb_results", "hltb_results.html", scraper.ExtractCandidatesHLTB},
+		{"completionator_results", "completionator_results.html", scraper.ExtractCandidatesCompletionator},
+		{"bing_results", "bing_results.html", extractWeb(t, scraper.WebSearchBing, "HLTB")},
+		{"duckduckgo_results", "duckduckgo_results.html", extractWeb(t, scraper.WebSearchDuckDuckGo, "HLTB")},
+		{"searxng_results", "searxng_results.html", extractWeb(t, scraper.WebSearchSearXNG, "Completionator")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pageHTML := readFixture(t, tt.fixture, base)
+			checkGolden(t, tt.name, base, tt.extract(string(pageHTML), base))
+		})
+	}
+}
+
+func TestScraperGameURL(t *testing.T) {
+	srv := newFixtureServer(t)
+
+	tests := []struct {
+		source string
+		link   string
+		want   string
+	}{
+		{"HLTB", srv.URL + "/game/42818", srv.URL + "/game/42818"},
+		{"HLTB", " " + srv.URL + "/game/42818/completions?s=1 ", srv.URL + "/game/42818"},
+		{"HLTB", strings.Replace(srv.URL, "http://", "https://", 1) + "/game/42818", srv.URL + "/game/42818"},
+		{"HLTB", srv.URL + "/Game/Details/3441", ""},
+		{"HLTB", "https://en.wikipedia.org/wiki/Celeste_(video_game)", ""},
+		{"Completionator", srv.URL + "/Game/Details/3441", srv.URL + "/Game/Details/3441"},
+		{"Completionator", srv.URL + "/game/details/3441/Reviews", srv.URL + "/Game/Details/3441"},
+		{"Completionator", srv.URL + "/game/42818", ""},
+	}
+	for _, tt := range tests {
+		src, _ := scraper.GetSource(tt.source)
+		got, ok := src.GameURL(tt.link)
+		if ok != (tt.want != "") || got != tt.want {
+			t.Errorf("%s GameURL(%q) = %q, %v, want %q", tt.source, tt.link, got, ok, tt.want)
+		}
+	}
+}
+
+// reads the results of the web search for games on the source
+func extractWeb(t *testing.T, search string, source string) func(pageHTML string, base string) []scraper.Candidate {
+	ws, ok := scraper.GetWebSearch(search)
+	if !ok {
+		t.Fatal("No web search named", search)
+	}
+	src, ok := scraper.GetSource(source)
+	if !ok {
+		t.Fatal("No source named", source)
+	}
+	return func(pageHTML string, base string) []scraper.Candidate {
+		return scraper.ExtractCandidatesWeb(pageHTML, ws, src.(scraper.WebSearchable), base)
+	}
+}
+
+func TestScraperWebSearchSettings(t *testing.T) {
+	t.Cleanup(func() { scraper.SetWebSearches(scraper.DefaultWebSearches) })
+
+	scraper.SetWebSearches([]string{scraper.WebSearchDuckDuckGo, scraper.WebSearchBing})
+	got := scraper.CurrentWebSearches()
+	if len(got) != 2 || got[0] != scraper.WebSearchDuckDuckGo || got[1] != scraper.WebSearchBing {
+		t.Errorf("expected the enabled web searches to be kept, got %v", got)
+	}
+
+	// no web search means a failed search of the site is not retried
+	scraper.SetWebSearches(nil)
+	if got := scraper.CurrentWebSearches(); len(got) != 0 {
+		t.Errorf("expected no web searches, got %v", got)
+	}
+}
+
+// a source whose own search finds nothing
+type failingSource struct{ scraper.Source }
+
+func (failingSource) Candidates(ctx context.Context, gameName string) ([]scraper.Candidate, error) {
+	return nil, scraper.ErrNoResults
+}
+
+// the same source, for a site whose games can be found with a web search
+type failingWebSource struct{ scraper.WebSearchable }
+
+func (failingWebSource) Candidates(ctx context.Context, gameName string) ([]scraper.Candidate, error) {
+	return nil, scraper.ErrNoResults
+}
+
+// a web search served by the stand-in server of the test that enabled it
+type testWebSearch struct{}
+
+var testWebSearchURL string
+
+func (testWebSearch) Name() string { return "Test" }
+func (testWebSearch) SearchURL(query string) string {
+	return testWebSearchURL + "/search?q=" + url.QueryEscape(query)
+}
+func (testWebSearch) Selectors() scraper.SearchSelectors { return scraper.SearchSelectors{Result: "a"} }
+func (testWebSearch) ResultLink(href string) string      { return href }
+
+func TestScraperWebSearchFallback(t *testing.T) {
+	useTestLimits(t)
+	if _, ok := scraper.GetWebSearch("Test"); !ok {
+		scraper.RegisterWebSearch(testWebSearch{})
+	}
+	scraper.SetWebSearches([]string{"Test"})
+	t.Cleanup(func() { scraper.SetWebSearches(scraper.DefaultWebSearches) })
+
+	// the web search is not allowed, so a search that reaches it fails without starting the browser
+	var searched atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		searched.Store(true)
+		w.Write([]byte("User-agent: *\nDisallow: /search\n"))
+	}))
+	defer srv.Close()
+	testWebSearchURL = srv.URL
+
+	hltb, _ := scraper.GetSource("HLTB")
+
+	// any source that can be found with a web search falls back to it
+	_, err := scraper.SearchCandidates(context.Background(), failingWebSource{hltb.(scraper.WebSearchable)}, "Celeste")
+	if !errors.Is(err, scraper.ErrNoResults) || !errors.Is(err, scraper.ErrDisallowed) {
+		t.Errorf("expected the errors of the site and of the web search, got %v", err)
+	}
+	if !searched.Load() {
+		t.Error("expected the web search to be tried")
+	}
+
+	// other sources only search their site
+	searched.Store(false)
+	if _, err := scraper.SearchCandidates(context.Background(), failingSource{hltb}, "Celeste"); !errors.Is(err, scraper.ErrNoResults) || errors.Is(err, scraper.ErrDisallowed) {
+		t.Errorf("expected only the error of the site, got %v", err)
+	}
+	if searched.Load() {
+		t.Error("expected no web search")
+	}
+}
+
+func TestScraperExtractCandidatesEmpty(t *testing.T) {
+	if got := scraper.ExtractCandidatesHLTB("<html><body>No results</body></html>", "http://fixture.test"); len(got) != 0 {
+		t.Errorf("expected no candidates, got %v", got)
+	}
+}
+
+func TestScraperCleanTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float32
+	}{
+		{"12 Hours", 12},
+		{"12½ Hours", 12.5},
+		{"½ Hours", 0.5},
+		{"--", -1},
+		{"100 Hours", 100},
+		{"7.5 Hours", 7.5},
+		{"45 Mins", 0.75},
+		{"1 Hour 30 Mins", 1.5},
+		{"1h 30m", 1.5},
+		{"2h15m", 2.25},
+		{"1:30", 1.5},
+		{"0:45:00", 0.75},
+		{"¾ Hours", 0.75},
+		{"1,250 Hours", 1250},
+		{"", -1},
+		{"N/A", -1},
+		{"3 Fortnights", -1},
+	}
+	for _, tt := range tests {
+		if got := scraper.CleanTime(tt.in); got != tt.want {
+			t.Errorf("CleanTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScraperCache(t *testing.T) {
+	useTestLimits(t)
+	dir := t.TempDir()
+	if err := scraper.SetCache(dir, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		scraper.SetCache("", scraper.DefaultCacheTTL)
+		scraper.SetOffline(false)
+	})
+
+	// the page only changes when its ETag does
+	var downloads, revalidations atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(readFixture(t, "hltb_game.html", ""))
+	}))
+	defer srv.Close()
+	link := srv.URL + "/game/42818"
+
+	fetch := func() scraper.Game {
+		t.Helper()
+		game, err := scraper.FetchHLTB(context.Background(), link)
+		if err != nil {
+			t.Fatal("Error fetching game:", err)
+		}
+		return game
+	}
+
+	// a cached page is used until it expires
+	first := fetch()
+	if second := fetch(); second.Name != first.Name || second.Main != first.Main {
+		t.Errorf("expected the cached page to give the same game, got %v", second)
+	}
+	if downloads.Load() != 1 || revalidations.Load() != 0 {
+		t.Errorf("expected one download, got %d downloads and %d revalidations", downloads.Load(), revalidations.Load())
+	}
+
+	// an expired page is checked with the site and not downloaded again when it has not changed
+	scraper.SetCache(dir, 0)
+	if game := fetch(); game.Main != first.Main {
+		t.Errorf("expected the revalidated page to give the same game, got %v", game)
+	}
+	if downloads.Load() != 1 || revalidations.Load() != 1 {
+		t.Errorf("expected one revalidation, got %d downloads and %d revalidations", downloads.Load(), revalidations.Load())
+	}
+
+	// offline, only cached pages are used, however old they are
+	scraper.SetOffline(true)
+	fetch()
+	if _, err := scraper.FetchHLTB(context.Background(), srv.URL+"/game/100"); !errors.Is(err, scraper.ErrOffline) {
+		t.Errorf("expected ErrOffline for a page that is not cached, got %v", err)
+	}
+	if err := scraper.ClearCache(); err != nil {
+		t.Fatal("Error clearing cache:", err)
+	}
+	if _, err := scraper.FetchHLTB(context.Background(), link); !errors.Is(err, scraper.ErrOffline) {
+		t.Errorf("expected ErrOffline once the cache is cleared, got %v", err)
+	}
+	if downloads.Load() != 1 || revalidations.Load() != 1 {
+		t.Errorf("expected no requests offline, got %d downloads and %d revalidations", downloads.Load(), revalidations.Load())
+	}
+}
+
+func TestScraperLoadSelectors(t *testing.T) {
+	live := scraper.CurrentSelectors()
+	t.Cleanup(func() { scraper.SetSelectors(live) })
+	path := filepath.Join(t.TempDir(), "selectors.yaml")
+
+	// a missing file is created with the default selectors
+	if err := scraper.LoadSelectors(path); err != nil {
+		t.Fatal("Error loading selectors:", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal("expected the selectors file to be created:", err)
+	}
+	defaults := scraper.CurrentSelectors()
+	if !reflect.DeepEqual(defaults, live) {
+		t.Error("expected the default selectors to be used")
+	}
+
+	// writes the selectors to the file with the version and a changed name selector for HLTB
+	writeSelectors := func(version int, name string) {
+		t.Helper()
+		s := defaults
+		s.Version = version
+		s.HLTB.Game.Name = name
+		data, err := yaml.Marshal(s)
+		if err != nil {
+			t.Fatal("Error writing selectors:", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal("Error writing selectors:", err)
+		}
+	}
+
+	// a file of the current version overrides the defaults
+	writeSelectors(defaults.Version, "h1.custom")
+	if err := scraper.LoadSelectors(path); err != nil {
+		t.Fatal("Error loading selectors:", err)
+	}
+	if got := scraper.CurrentSelectors().HLTB.Game.Name; got != "h1.custom" {
+		t.Errorf("expected the selector of the file, got %q", got)
+	}
+
+	// a file of an older version is kept as .old and replaced with the defaults
+	writeSelectors(defaults.Version-1, "h1.older")
+	if err := scraper.LoadSelectors(path); err != nil {
+		t.Fatal("Error loading selectors:", err)
+	}
+	if !reflect.DeepEqual(scraper.CurrentSelectors(), defaults) {
+		t.Error("expected the default selectors to replace an older file")
+	}
+	if old, err := os.ReadFile(path + ".old"); err != nil || !strings.Contains(string(old), "h1.older") {
+		t.Errorf("expected the older file to be kept as .old, got %v", err)
+	}
+	if err := scraper.LoadSelectors(path); err != nil || !reflect.DeepEqual(scraper.CurrentSelectors(), defaults) {
+		t.Errorf("expected the file to now hold the defaults, got %v", err)
+	}
+
+	// a file that cannot be used gives the defaults, even after other selectors were loaded
+	for _, contents := range []string{"hltb: [", "version: 999\nhltb:\n  search:\n    result: a\n"} {
+		writeSelectors(defaults.Version, "h1.custom")
+		scraper.LoadSelectors(path)
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal("Error writing selectors:", err)
+		}
+		if err := scraper.LoadSelectors(path); !errors.Is(err, scraper.ErrInvalidSelectors) {
+			t.Errorf("expected ErrInvalidSelectors for %q, got %v", contents, err)
+		}
+		if !reflect.DeepEqual(scraper.CurrentSelectors(), defaults) {
+			t.Errorf("expected the default selectors to be kept for %q", contents)
+		}
+	}
+}